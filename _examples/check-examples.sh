@@ -0,0 +1,25 @@
+#!/bin/bash
+
+set -euo pipefail
+cd ${0%/*}
+
+status=0
+for f in $(find . -type f -name "*.go"); do
+  ENVDOC_CHECK=1 go generate "$f" || status=1
+done
+
+# -check mode verifies only outputs of existing directives,
+# rebuild examples from scratch to find stale and missing files too.
+./clean.sh > /dev/null
+./build-examples.sh
+if ! git diff --exit-code -- .; then
+  echo "examples are dirty, rebuild it locally before commiting"
+  status=1
+fi
+untracked=$(git ls-files --others --exclude-standard -- .)
+if [ -n "$untracked" ]; then
+  echo "untracked example files, commit or remove them:"
+  echo "$untracked"
+  status=1
+fi
+exit $status
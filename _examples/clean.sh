@@ -3,4 +3,4 @@
 set -euo pipefail
 cd ${0%/*}
 
-find . -type f \( -name "*.md" -or -name '*.txt' -or -name '*.html' -or -name '*.env' \) ! -name "README.md" -exec rm -v {} \;
+find . -type f \( -name "*.md" -or -name '*.txt' -or -name '*.html' -or -name '*.env' -or -name '*.yml' -or -name '*.dockerfile' -or -name '*.7' -or -name '*.adoc' -or -name '*.rst' -or -name '*.json' -or -name 'doc.sh' -or -name '*_gen.go' \) ! -name "README.md" -exec rm -v {} \;
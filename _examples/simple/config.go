@@ -9,6 +9,14 @@ package main
 //go:generate go run ../../ -output doc.html -format html
 //go:generate go run ../../ -output doc.env -format dotenv
 //go:generate go run ../../ -output doc.json -format json
+//go:generate go run ../../ -output doc.compose.yml -format compose
+//go:generate go run ../../ -output doc.dockerfile -format dockerfile
+//go:generate go run ../../ -output doc.systemd.env -format systemd
+//go:generate go run ../../ -output doc.sh -format shell
+//go:generate go run ../../ -output doc.7 -format man
+//go:generate go run ../../ -output doc.adoc -format asciidoc
+//go:generate go run ../../ -output doc.rst -format rst
+//go:generate go run ../../ -output env_gen.go -format go
 type Config struct {
 	// Hosts name of hosts to listen on.
 	Hosts []string `env:"HOST,required", envSeparator:";"`
@@ -0,0 +1,30 @@
+#!/bin/sh
+# Environment Variables
+
+envdoc_missing=""
+[ -n "${HOST+x}" ] || envdoc_missing="$envdoc_missing HOST"
+[ -n "${PORT:-}" ] || envdoc_missing="$envdoc_missing PORT"
+if [ -n "$envdoc_missing" ]; then
+  echo "missing required environment variables:$envdoc_missing" >&2
+  exit 1
+fi
+
+## Config
+## Config is an example configuration structure.
+## It is used to generate documentation for the configuration
+## using the commands below.
+#
+# Hosts name of hosts to listen on.
+# (separated by ';', required)
+export HOST
+
+# Port to listen on.
+# (required, non-empty)
+export PORT
+
+# Debug mode enabled.
+# (default: 'false')
+export DEBUG="${DEBUG:-false}"
+
+# Prefix for something.
+export PREFIX
@@ -0,0 +1,112 @@
+package ast
+
+import (
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"io/fs"
+	"strings"
+)
+
+// buildFilter returns a file filter of parser.ParseDir which accepts files
+// matching build context, or nil to accept all files if ctx is nil.
+func buildFilter(ctx *build.Context, dir string) func(fs.FileInfo) bool {
+	if ctx == nil {
+		return nil
+	}
+	return func(fi fs.FileInfo) bool {
+		match, err := ctx.MatchFile(dir, fi.Name())
+		// let the parser report errors of invalid files
+		return match || err != nil
+	}
+}
+
+// buildContextKey identifies parse results of build context in parse cache.
+func buildContextKey(ctx *build.Context) string {
+	if ctx == nil {
+		return "all"
+	}
+	return ctx.GOOS + "/" + ctx.GOARCH + "/" + strings.Join(ctx.BuildTags, ",")
+}
+
+// buildConstraint returns build constraint expression of file: GOOS and GOARCH
+// of file name suffix and //go:build line. It returns empty string
+// if file has no build constraints.
+func buildConstraint(name string, f *ast.File) string {
+	var exprs []string
+	goos, goarch := fileNameConstraint(name)
+	if goos != "" {
+		exprs = append(exprs, goos)
+	}
+	if goarch != "" {
+		exprs = append(exprs, goarch)
+	}
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			s := expr.String()
+			if _, ok := expr.(*constraint.OrExpr); ok && len(exprs) > 0 {
+				s = "(" + s + ")"
+			}
+			exprs = append(exprs, s)
+		}
+	}
+	return strings.Join(exprs, " && ")
+}
+
+// fileNameConstraint returns GOOS and GOARCH of file name suffixes,
+// e.g. config_linux_amd64.go, the same way as go/build does.
+func fileNameConstraint(name string) (goos, goarch string) {
+	name = name[strings.LastIndexAny(name, `/\`)+1:]
+	name, _, _ = strings.Cut(name, ".")
+	// the first part is never a suffix, e.g. linux.go has no constraints
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return "", ""
+	}
+	parts := strings.Split(name[i:], "_")
+	if parts[len(parts)-1] == "test" {
+		parts = parts[:len(parts)-1]
+	}
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2], parts[n-1]
+	}
+	if n >= 1 {
+		if knownOS[parts[n-1]] {
+			return parts[n-1], ""
+		}
+		if knownArch[parts[n-1]] {
+			return "", parts[n-1]
+		}
+	}
+	return "", ""
+}
+
+// knownOS and knownArch are GOOS and GOARCH values of go/build/syslist.go.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+		"js": true, "linux": true, "nacl": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true,
+		"armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+		"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+		"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true,
+		"s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+	}
+)
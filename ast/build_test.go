@@ -0,0 +1,34 @@
+package ast
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestBuildConstraint(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		src    string
+		expect string
+	}{
+		{"config.go", "package a\n", ""},
+		{"linux.go", "package a\n", ""},
+		{"config_linux.go", "package a\n", "linux"},
+		{"config_linux_arm64.go", "package a\n", "linux && arm64"},
+		{"config_amd64_test.go", "package a\n", "amd64"},
+		{"config.go", "//go:build debug && !race\n\npackage a\n", "debug && !race"},
+		{"config_windows.go", "//go:build debug || trace\n\npackage a\n", "windows && (debug || trace)"},
+		{"config.go", "// Package a doc.\npackage a\n\n//go:build debug\n", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), tc.name, tc.src, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if res := buildConstraint(tc.name, f); res != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, res)
+			}
+		})
+	}
+}
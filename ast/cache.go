@@ -0,0 +1,112 @@
+package ast
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"sync"
+
+	"github.com/g4s8/envdoc/debug"
+)
+
+// ParseCache keeps parsed packages and collected files of directories,
+// so each directory is parsed and collected only once if the parser is running
+// multiple times, e.g. for all go:generate directives of a repository.
+// Collected files don't depend on parser type and file globs,
+// each parser run gets copies of them with its own export flags.
+// It's safe for concurrent use.
+type ParseCache struct {
+	mux       sync.Mutex
+	fset      *token.FileSet
+	entries   map[string]*parseCacheEntry
+	collected map[string]*collectCacheEntry
+
+	hits, misses int
+}
+
+type parseCacheEntry struct {
+	once sync.Once
+	pkgs map[string]*ast.Package //nolint:staticcheck
+	err  error
+}
+
+type collectCacheEntry struct {
+	once  sync.Once
+	files []*FileSpec
+	err   error
+}
+
+// NewParseCache creates empty parse cache.
+func NewParseCache() *ParseCache {
+	return &ParseCache{
+		fset:      token.NewFileSet(),
+		entries:   make(map[string]*parseCacheEntry),
+		collected: make(map[string]*collectCacheEntry),
+	}
+}
+
+// Stats returns number of cache hits and misses of collected directories.
+func (c *ParseCache) Stats() (hits, misses int) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.hits, c.misses
+}
+
+// parseDir returns cached packages of dir or parses it, path is dir resolved
+// against the working dir of the parser. File names of parsed packages depend
+// on dir, so the key is both absolute path and dir, and the build context key,
+// since it filters parsed files. Different directories are parsed concurrently,
+// the same directory is parsed once.
+func (c *ParseCache) parseDir(dir, path, ctxKey string, parse parseFunc) (map[string]*ast.Package, error) { //nolint:staticcheck
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	key := abs + "\x00" + dir + "\x00" + ctxKey
+
+	c.mux.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = new(parseCacheEntry)
+		c.entries[key] = e
+	}
+	c.mux.Unlock()
+
+	e.once.Do(func() {
+		e.pkgs, e.err = parse(dir, c.fset)
+	})
+	return e.pkgs, e.err
+}
+
+// collectDir returns cached files of dir collected relative to root dir,
+// or parses and collects them. Files of the same dir are collected once
+// for each root dir and build context, by parser of the first call logging to log.
+// Path is dir resolved against the working dir of the parser.
+// Returned files must not be modified, use RootCollector.exportFiles to get files
+// with export flags.
+func (c *ParseCache) collectDir(root, dir, path, ctxKey string, parse parseFunc, log debug.Logger) ([]*FileSpec, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	key := root + "\x00" + abs + "\x00" + dir + "\x00" + ctxKey
+
+	c.mux.Lock()
+	e, ok := c.collected[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+		e = new(collectCacheEntry)
+		c.collected[key] = e
+	}
+	c.mux.Unlock()
+
+	e.once.Do(func() {
+		pkgs, err := c.parseDir(dir, path, ctxKey, parse)
+		col := NewRootCollector(root, WithCollectorLogger(log))
+		walkPackages(pkgs, c.fset, col, log)
+		e.files, e.err = col.files, err
+	})
+	return e.files, e.err
+}
@@ -0,0 +1,97 @@
+package ast
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCache(t *testing.T) {
+	dir := t.TempDir()
+	src := "package a\n\n// Config doc.\ntype Config struct {\n\t// Port doc.\n\tPort int `env:\"PORT\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(src), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	cache := NewParseCache()
+	for i := 0; i < 3; i++ {
+		p := NewParser("*", "Config", WithParseCache(cache))
+		files, err := p.Parse(dir)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if len(files) != 1 || len(files[0].Types) != 1 || files[0].Types[0].Name != "Config" {
+			t.Fatalf("unexpected files on run %d: %+v", i, files)
+		}
+	}
+	hits, misses := cache.Stats()
+	if hits != 2 || misses != 1 {
+		t.Fatalf("unexpected cache stats: %d hits, %d misses", hits, misses)
+	}
+}
+
+func TestParseCacheExport(t *testing.T) {
+	dir := t.TempDir()
+	src := "package a\n\n//go:generate envdoc -output a.md\ntype Config struct {\n\tPort int `env:\"PORT\"`\n}\n\n" +
+		"//go:generate envdoc -output b.md\ntype Other struct {\n\tHost string `env:\"HOST\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(src), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	// export flags of shared files depend on options of each parser
+	cache := NewParseCache()
+	for _, c := range []struct {
+		name   string
+		opts   []ParserConfigOption
+		types  string
+		export string
+	}{
+		{name: "type glob", types: "Other", export: "Other"},
+		{name: "gogen decl", opts: []ParserConfigOption{WithExecConfig("./a.go", 3)}, export: "Config"},
+		{name: "gogen decl of other type", opts: []ParserConfigOption{WithExecConfig("./a.go", 8)}, export: "Other"},
+		{name: "directives", export: "Config,Other"},
+		{name: "all types", types: "*", export: "Config,Other"},
+	} {
+		opts := append([]ParserConfigOption{WithParseCache(cache)}, c.opts...)
+		files, err := NewParser("", c.types, opts...).Parse(dir)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", c.name, err)
+		}
+		var export []string
+		for _, tpe := range files[0].Types {
+			if tpe.Export {
+				export = append(export, tpe.Name)
+			}
+		}
+		if actual := strings.Join(export, ","); actual != c.export {
+			t.Errorf("%s: expected exported types %q, got %q", c.name, c.export, actual)
+		}
+	}
+	if hits, misses := cache.Stats(); hits != 4 || misses != 1 {
+		t.Fatalf("unexpected cache stats: %d hits, %d misses", hits, misses)
+	}
+}
+
+func TestParseCacheDirectiveLines(t *testing.T) {
+	src := "package a\n\n//go:generate envdoc -output a.md\ntype Config struct {\n\tPort int `env:\"PORT\"`\n}\n"
+	dirs := []string{t.TempDir(), t.TempDir()}
+	for _, dir := range dirs {
+		if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(src), 0o600); err != nil {
+			t.Fatalf("write source: %v", err)
+		}
+	}
+
+	// files of all dirs share the file set of the cache,
+	// lines of directives are the same as in the first parsed file
+	cache := NewParseCache()
+	for _, dir := range dirs {
+		files, err := NewParser("", "", WithParseCache(cache), WithExecConfig("./a.go", 3)).Parse(dir)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if len(files) != 1 || len(files[0].Types) != 1 || !files[0].Types[0].Export {
+			t.Fatalf("expected exported type of %s: %+v", dir, files[0].Types)
+		}
+	}
+}
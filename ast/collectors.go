@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"slices"
 	"sort"
 	"strings"
 
@@ -33,6 +34,21 @@ func WithGoGenDecl(line int, file string) RootCollectorOption {
 	}
 }
 
+// WithCollectorLogger sets a logger of collected files.
+func WithCollectorLogger(log debug.Logger) RootCollectorOption {
+	return func(c *RootCollector) {
+		c.log = log
+	}
+}
+
+// WithDirectives exports types declared after envdoc go:generate directives,
+// it's used when envdoc is running outside of go generate.
+func WithDirectives() RootCollectorOption {
+	return func(c *RootCollector) {
+		c.directives = true
+	}
+}
+
 var (
 	_ interface {
 		FileHandler
@@ -55,10 +71,14 @@ type RootCollector struct {
 		line int
 		file string
 	}
+	directives bool
+	log        debug.Logger
 
 	// pendingType is true if gogen declaration was specified
 	// and the next type will be the expected one
 	pendingType bool
+	// comments are comments of the current file since the previous type
+	comments []*CommentSpec
 
 	files []*FileSpec
 }
@@ -70,6 +90,7 @@ func NewRootCollector(baseDir string, opts ...RootCollectorOption) *RootCollecto
 		baseDir:  baseDir,
 		fileGlob: globAcceptAll,
 		typeGlob: globAcceptAll,
+		log:      debug.NopLogger(),
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -103,11 +124,14 @@ func (c *RootCollector) onFile(f *FileSpec) interface {
 		name = "./" + name
 	}
 	f.Name = name
+	// directive of previous file can't declare types of this file
+	c.pendingType = false
+	c.comments = nil
 
 	if c.fileGlob(f.Name) {
 		f.Export = true
 	}
-	debug.Logf("# COL: file %q, export=%t\n", f.Name, f.Export)
+	c.log.Logf("# COL: file %q, export=%t\n", f.Name, f.Export)
 	c.files = append(c.files, f)
 	return c
 }
@@ -130,30 +154,62 @@ func (c *RootCollector) onType(tpe *TypeSpec) interface {
 } {
 	currentFile := c.currentFile()
 
-	var export bool
-	if c.gogenDecl != nil {
-		if c.pendingType {
-			c.pendingType = false
-			export = true
-		}
-	} else if c.typeGlob(tpe.Name) {
-		export = true
-	}
-
-	tpe.Export = export
+	tpe.Export = c.typeExported(tpe.Name, c.pendingType)
+	tpe.comments = c.comments
+	c.pendingType = false
+	c.comments = nil
 	currentFile.Types = append(currentFile.Types, tpe)
 	return &TypeCollector{spec: tpe}
 }
 
 func (c *RootCollector) setComment(spec *CommentSpec) {
 	currentFile := c.currentFile()
+	c.comments = append(c.comments, spec)
+	if c.isDirective(currentFile.Name, spec) {
+		c.pendingType = true
+	}
+}
 
-	if c.gogenDecl == nil {
-		return
+// isDirective checks if comment of file declares the next type as documented:
+// it's the go:generate directive of the running envdoc, or any envdoc
+// directive if envdoc is running outside of go generate.
+func (c *RootCollector) isDirective(file string, spec *CommentSpec) bool {
+	if c.directives {
+		_, ok := ParseDirective(spec.Text)
+		return ok
 	}
-	if c.gogenDecl.file == currentFile.Name && c.gogenDecl.line == spec.Line {
-		c.pendingType = true
+	return c.gogenDecl != nil && c.gogenDecl.file == file && c.gogenDecl.line == spec.Line
+}
+
+// typeExported checks if type should be exported: it's declared after
+// a directive in directive modes, or its name is matched by type glob.
+func (c *RootCollector) typeExported(name string, afterDirective bool) bool {
+	if c.gogenDecl != nil || c.directives {
+		return afterDirective
 	}
+	return c.typeGlob(name)
+}
+
+// exportFiles returns copies of files collected by another collector
+// of the same base dir, with export flags of this collector options.
+// Fields of types are not copied, they are shared with original files.
+func (c *RootCollector) exportFiles(files []*FileSpec) []*FileSpec {
+	res := make([]*FileSpec, len(files))
+	for i, f := range files {
+		fc := *f
+		fc.Export = c.fileGlob(f.Name)
+		fc.Types = make([]*TypeSpec, len(f.Types))
+		for j, t := range f.Types {
+			tc := *t
+			afterDirective := slices.ContainsFunc(t.comments, func(spec *CommentSpec) bool {
+				return c.isDirective(f.Name, spec)
+			})
+			tc.Export = c.typeExported(t.Name, afterDirective)
+			fc.Types[j] = &tc
+		}
+		res[i] = &fc
+	}
+	return res
 }
 
 type TypeCollector struct {
@@ -3,31 +3,32 @@
 package ast
 
 import (
-	"fmt"
 	"strings"
+
+	"github.com/g4s8/envdoc/debug"
 )
 
-func printTraverse(files []*FileSpec, level int) {
+func printTraverse(log debug.Logger, files []*FileSpec, level int) {
 	indent := strings.Repeat("  ", level)
 	for _, file := range files {
-		fmt.Printf("%sFILE:%q\n", indent, file.Name)
-		printTraverseTypes(file.Types, level+1)
+		log.Logf("%sFILE:%q\n", indent, file.Name)
+		printTraverseTypes(log, file.Types, level+1)
 	}
 }
 
-func printTraverseTypes(types []*TypeSpec, level int) {
+func printTraverseTypes(log debug.Logger, types []*TypeSpec, level int) {
 	indent := strings.Repeat("  ", level)
 	for _, t := range types {
-		fmt.Printf("%sTYPE:%q; doc: %q\n", indent, t.Name, t.Doc)
-		printTraverseFields(t.Fields, level+1)
+		log.Logf("%sTYPE:%q at %s; doc: %q\n", indent, t.Name, t.Pos, t.Doc)
+		printTraverseFields(log, t.Fields, level+1)
 	}
 }
 
-func printTraverseFields(fields []*FieldSpec, level int) {
+func printTraverseFields(log debug.Logger, fields []*FieldSpec, level int) {
 	indent := strings.Repeat("  ", level)
 	for _, f := range fields {
 		names := strings.Join(f.Names, ", ")
-		fmt.Printf("%sFIELD:%s (%s); doc: %q\n", indent, names, f.TypeRef.String(), f.Doc)
-		printTraverseFields(f.Fields, level+1)
+		log.Logf("%sFIELD:%s (%s) at %s; doc: %q\n", indent, names, f.TypeRef.String(), f.Pos, f.Doc)
+		printTraverseFields(log, f.Fields, level+1)
 	}
 }
@@ -2,4 +2,6 @@
 
 package ast
 
-func printTraverse(files []*FileSpec, level int) {}
+import "github.com/g4s8/envdoc/debug"
+
+func printTraverse(log debug.Logger, files []*FileSpec, level int) {}
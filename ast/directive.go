@@ -0,0 +1,96 @@
+package ast
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ParseDirective parses go:generate comment text of envdoc command
+// and returns envdoc arguments. It returns false if the comment is not
+// an envdoc directive. Supported commands:
+//
+//	go:generate envdoc ...
+//	go:generate go tool envdoc ...
+//	go:generate go run github.com/g4s8/envdoc@latest ...
+func ParseDirective(text string) ([]string, bool) {
+	text = strings.TrimPrefix(text, "//")
+	cmd, ok := strings.CutPrefix(text, "go:generate ")
+	if !ok {
+		return nil, false
+	}
+	words, ok := splitDirective(cmd)
+	if !ok || len(words) == 0 {
+		return nil, false
+	}
+	if isEnvdocCommand(words[0]) {
+		return words[1:], true
+	}
+	if words[0] != "go" || len(words) < 3 {
+		return nil, false
+	}
+	switch words[1] {
+	case "tool":
+		if isEnvdocCommand(words[2]) {
+			return words[3:], true
+		}
+	case "run":
+		// skip go run flags
+		for i := 2; i < len(words); i++ {
+			if strings.HasPrefix(words[i], "-") {
+				continue
+			}
+			if isEnvdocCommand(words[i]) {
+				return words[i+1:], true
+			}
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// isEnvdocCommand checks if command is envdoc binary or package path.
+func isEnvdocCommand(cmd string) bool {
+	cmd, _, _ = strings.Cut(cmd, "@")
+	return path.Base(cmd) == "envdoc"
+}
+
+// splitDirective splits command line into words the same way as go generate:
+// words are separated by spaces, double-quoted strings are unquoted.
+func splitDirective(line string) ([]string, bool) {
+	var words []string
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return words, true
+		}
+		if line[0] == '"' {
+			end := 1
+			for ; end < len(line); end++ {
+				if line[end] == '\\' {
+					end++
+					continue
+				}
+				if line[end] == '"' {
+					break
+				}
+			}
+			if end >= len(line) {
+				return nil, false
+			}
+			word, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, false
+			}
+			words = append(words, word)
+			line = line[end+1:]
+			continue
+		}
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		words = append(words, line[:end])
+		line = line[end:]
+	}
+}
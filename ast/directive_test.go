@@ -0,0 +1,45 @@
+package ast
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseDirective(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		args []string
+		ok   bool
+	}{
+		{"go:generate envdoc -output doc.md", []string{"-output", "doc.md"}, true},
+		{"//go:generate envdoc -output doc.md", []string{"-output", "doc.md"}, true},
+		{"go:generate envdoc", []string{}, true},
+		{"go:generate /usr/local/bin/envdoc -types '*'", []string{"-types", "'*'"}, true},
+		{"go:generate go tool envdoc -format dotenv", []string{"-format", "dotenv"}, true},
+		{
+			"go:generate go run github.com/g4s8/envdoc@latest -output doc.md",
+			[]string{"-output", "doc.md"}, true,
+		},
+		{
+			"go:generate go run -mod=mod github.com/g4s8/envdoc -output doc.md",
+			[]string{"-output", "doc.md"}, true,
+		},
+		{`go:generate envdoc -env-prefix "APP " -output doc.md`, []string{"-env-prefix", "APP ", "-output", "doc.md"}, true},
+		{"go:generate stringer -type Foo", nil, false},
+		{"go:generate go run ./cmd/gen", nil, false},
+		{"go:generate go tool stringer", nil, false},
+		{`go:generate envdoc -env-prefix "APP`, nil, false},
+		{"envdoc -output doc.md", nil, false},
+		{"Config is envdoc config", nil, false},
+	} {
+		t.Run(tc.text, func(t *testing.T) {
+			args, ok := ParseDirective(tc.text)
+			if ok != tc.ok {
+				t.Fatalf("Expected ok=%v, got %v", tc.ok, ok)
+			}
+			if ok && !slices.Equal(args, tc.args) {
+				t.Errorf("Expected args %q, got %q", tc.args, args)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package ast
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// diskCacheFormat is a version of cache entries format,
+// it should be changed if FileSpec is changed.
+const diskCacheFormat = "3"
+
+// DiskCache is an on-disk cache of parsed files. Each entry holds files
+// of one directory, it's keyed by paths and content hashes of source files,
+// parser options and envdoc version, so unchanged directories are not parsed
+// again by subsequent runs. It's safe for concurrent use.
+type DiskCache struct {
+	dir     string
+	version string
+
+	mux          sync.Mutex
+	hits, misses int
+}
+
+// NewDiskCache creates disk cache in dir, entries of other
+// envdoc versions are not used.
+func NewDiskCache(dir, version string) *DiskCache {
+	return &DiskCache{dir: dir, version: version}
+}
+
+// DefaultDiskCacheDir returns envdoc directory in the user cache dir.
+func DefaultDiskCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("get user cache dir: %w", err)
+	}
+	return filepath.Join(dir, "envdoc"), nil
+}
+
+// Stats returns number of cache hits and misses.
+func (c *DiskCache) Stats() (hits, misses int) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.hits, c.misses
+}
+
+type diskCacheEntry struct {
+	Files []*FileSpec `json:"files"`
+}
+
+// key returns cache key of dir: it hashes scope of parser options and
+// names and content of Go files of dir accepted by filter, the same
+// files as parser.ParseDir parses.
+func (c *DiskCache) key(dir, scope string, filter func(fs.FileInfo) bool) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00", diskCacheFormat, c.version, scope, abs, dir)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		if filter != nil {
+			info, err := e.Info()
+			if err != nil {
+				return "", err
+			}
+			if !filter(info) {
+				continue
+			}
+		}
+		if err := hashFile(h, filepath.Join(dir, e.Name())); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(h io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fh := sha256.New()
+	if _, err := io.Copy(fh, f); err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "%s\x00%x\x00", filepath.Base(path), fh.Sum(nil))
+	return nil
+}
+
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// load returns cached files of key, it counts hits and misses.
+func (c *DiskCache) load(key string) ([]*FileSpec, bool) {
+	var entry diskCacheEntry
+	data, err := os.ReadFile(c.path(key))
+	if err == nil {
+		err = json.Unmarshal(data, &entry)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if err != nil {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return entry.Files, true
+}
+
+// store writes files of key to the cache, the entry file is replaced
+// atomically, so concurrent runs never read partially written entries.
+func (c *DiskCache) store(key string, files []*FileSpec) error {
+	data, err := json.Marshal(diskCacheEntry{Files: files})
+	if err != nil {
+		return fmt.Errorf("marshal cache entry: %w", err)
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cache entry: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	return nil
+}
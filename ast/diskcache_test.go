@@ -0,0 +1,64 @@
+package ast
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	writeSyntheticTree(t, dir, 3, 2)
+	cache := NewDiskCache(t.TempDir(), "test")
+
+	parse := func(t *testing.T, typeGlob string) []*FileSpec {
+		t.Helper()
+		files, err := NewParser("*", typeGlob, WithDiskCache(cache)).Parse(dir)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		return files
+	}
+	checkStats := func(t *testing.T, hits, misses int) {
+		t.Helper()
+		if h, m := cache.Stats(); h != hits || m != misses {
+			t.Fatalf("expected %d hits and %d misses, got %d and %d", hits, misses, h, m)
+		}
+	}
+
+	expect, err := NewParser("*", "*").Parse(dir)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	// root dir, 1 package dir and 3 sub dirs
+	checkFiles(t, "/miss", expect, parse(t, "*"))
+	checkStats(t, 0, 5)
+	checkFiles(t, "/hit", expect, parse(t, "*"))
+	checkStats(t, 5, 5)
+
+	// parser options are part of the key
+	parse(t, "Config0")
+	checkStats(t, 5, 10)
+
+	// changed files are parsed again
+	file := filepath.Join(dir, "pkg000", "sub001", "config1.go")
+	if err := os.WriteFile(file, []byte("package sub001\n\ntype Changed struct {\n\tFoo int `env:\"FOO\"`\n}\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	files := parse(t, "*")
+	checkStats(t, 9, 11)
+	var found bool
+	for _, f := range files {
+		for _, typ := range f.Types {
+			found = found || typ.Name == "Changed"
+		}
+	}
+	if !found {
+		t.Fatal("changed type not found")
+	}
+
+	// other versions don't use the same entries
+	cache = NewDiskCache(cache.dir, "other")
+	parse(t, "*")
+	checkStats(t, 0, 5)
+}
@@ -1,20 +1,27 @@
 package ast
 
-import "go/ast"
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/g4s8/envdoc/debug"
+)
 
 type fieldVisitor struct {
-	pkg string
-	h   FieldHandler
+	fset *token.FileSet
+	pkg  string
+	h    FieldHandler
+	log  debug.Logger
 
 	nested bool
 }
 
-func newFieldVisitor(pkg string, h FieldHandler) *fieldVisitor {
-	return &fieldVisitor{pkg: pkg, h: h}
+func newFieldVisitor(fset *token.FileSet, pkg string, h FieldHandler, log debug.Logger) *fieldVisitor {
+	return &fieldVisitor{fset: fset, pkg: pkg, h: h, log: log}
 }
 
 func (v *fieldVisitor) Visit(n ast.Node) ast.Visitor {
-	debugNode("field", n)
+	debugNode(v.log, "field", n)
 	switch t := n.(type) {
 	case *ast.StructType:
 		v.nested = true
@@ -23,12 +30,12 @@ func (v *fieldVisitor) Visit(n ast.Node) ast.Visitor {
 		if !v.nested {
 			return nil
 		}
-		fs := getFieldSpec(t, v.pkg)
+		fs := getFieldSpec(v.fset, t, v.pkg)
 		if fs == nil {
 			return nil
 		}
 		if fa := v.h.onField(fs); fa != nil {
-			return newFieldVisitor(v.pkg, fa)
+			return newFieldVisitor(v.fset, v.pkg, fa, v.log)
 		}
 	}
 	return v
@@ -20,19 +20,23 @@ type fileVisitor struct {
 	file *ast.File
 	docs *doc.Package
 	h    fileVisitorHandler
+	log  debug.Logger
 }
 
-func newFileVisitor(fset *token.FileSet, file *ast.File, docs *doc.Package, h fileVisitorHandler) *fileVisitor {
+func newFileVisitor(fset *token.FileSet, file *ast.File, docs *doc.Package, h fileVisitorHandler,
+	log debug.Logger,
+) *fileVisitor {
 	return &fileVisitor{
 		fset: fset,
 		file: file,
 		docs: docs,
 		h:    h,
+		log:  log,
 	}
 }
 
 func (v *fileVisitor) Visit(n ast.Node) ast.Visitor {
-	debugNode("file", n)
+	debugNode(v.log, "file", n)
 	switch t := n.(type) {
 	case *ast.ImportSpec:
 		var spec ImportSpec
@@ -42,11 +46,11 @@ func (v *fileVisitor) Visit(n ast.Node) ast.Visitor {
 		path := strings.TrimPrefix(t.Path.Value, "\"")
 		path = strings.TrimSuffix(path, "\"")
 		spec.Path = path
-		debug.Logf("# V: import %q, name=%q\n", spec.Path, spec.Name)
+		v.log.Logf("# V: import %q, name=%q\n", spec.Path, spec.Name)
 		v.h.addImport(&spec)
 		return nil
 	case *ast.Comment:
-		line := findCommentLine(t, v.fset, v.file)
+		line := findCommentLine(t, v.fset)
 		text := strings.TrimPrefix(t.Text, "//")
 		text = strings.TrimSpace(text)
 		v.h.setComment(&CommentSpec{
@@ -59,8 +63,9 @@ func (v *fileVisitor) Visit(n ast.Node) ast.Visitor {
 		if ta := v.h.onType(&TypeSpec{
 			Name: t.Name.Name,
 			Doc:  doc,
+			Pos:  getPosition(v.fset, t.Pos()),
 		}); ta != nil {
-			return newTypeVisitor(v.file.Name.String(), ta)
+			return newTypeVisitor(v.fset, v.file.Name.String(), ta, v.log)
 		}
 		return nil
 	}
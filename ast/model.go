@@ -1,6 +1,9 @@
 package ast
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 //go:generate stringer -type=FieldTypeRefKind -trimprefix=FieldType
 type FieldTypeRefKind int
@@ -21,13 +24,21 @@ type (
 		Imports []*ImportSpec
 		Types   []*TypeSpec
 		Export  bool // tru if file should be exported
+		// BuildConstraint is a build constraint expression of the file,
+		// including GOOS and GOARCH file name suffixes.
+		BuildConstraint string
 	}
 
 	TypeSpec struct {
 		Name   string
 		Doc    string
 		Fields []*FieldSpec
-		Export bool // true if type should be exported
+		Export bool     // true if type should be exported
+		Pos    Position // position of type declaration
+
+		// comments are file comments between the previous type and this one,
+		// they are used to export types of cached files by directives.
+		comments []*CommentSpec
 	}
 
 	FieldSpec struct {
@@ -36,12 +47,21 @@ type (
 		Tag     string
 		TypeRef FieldTypeRef
 		Fields  []*FieldSpec
+		Pos     Position // position of field declaration
+	}
+
+	// Position is a source position of declaration.
+	Position struct {
+		File string
+		Line int
 	}
 
 	FieldTypeRef struct {
 		Name string
 		Pkg  string
 		Kind FieldTypeRefKind
+		// Key is a key type of map kind as written in source, e.g. int or time.Duration.
+		Key string
 	}
 
 	DocSpec struct {
@@ -103,7 +123,7 @@ func (tr FieldTypeRef) String() string {
 	case FieldTypeArray:
 		return "[]" + tr.Name
 	case FieldTypeMap:
-		return "map[string]" + tr.Name
+		return "map[" + tr.Key + "]" + tr.Name
 	case FieldTypeStruct:
 		return "struct"
 	}
@@ -150,6 +170,13 @@ func (fs *FieldSpec) String() string {
 	return strings.Join(fs.Names, ", ")
 }
 
+func (p Position) String() string {
+	if p.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
 func (i *ImportSpec) PathName() string {
 	// convert path `github.com/g4s8/envdoc/ast` to `ast`
 	if i.Path == "" {
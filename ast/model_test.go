@@ -23,7 +23,8 @@ func TestFieldTypeRef(t *testing.T) {
 		{FieldTypeRef{Name: "MyType", Pkg: "mypkg", Kind: FieldTypeSelector}, "mypkg.MyType"},
 		{FieldTypeRef{Name: "MyType", Kind: FieldTypePtr}, "*MyType"},
 		{FieldTypeRef{Name: "MyType", Kind: FieldTypeArray}, "[]MyType"},
-		{FieldTypeRef{Name: "MyType", Kind: FieldTypeMap}, "map[string]MyType"},
+		{FieldTypeRef{Name: "MyType", Kind: FieldTypeMap, Key: "string"}, "map[string]MyType"},
+		{FieldTypeRef{Name: "MyType", Kind: FieldTypeMap, Key: "int"}, "map[int]MyType"},
 		{FieldTypeRef{Name: "MyType", Kind: FieldTypeStruct}, "struct"},
 	}
 	for _, tc := range cases {
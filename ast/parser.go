@@ -2,11 +2,20 @@ package ast
 
 import (
 	"fmt"
+	"go/ast"
+	"go/build"
 	"go/parser"
 	"go/token"
+	"io"
 	"io/fs"
+	"maps"
+	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 
+	"github.com/g4s8/envdoc/debug"
 	"github.com/g4s8/envdoc/utils"
 )
 
@@ -25,18 +34,110 @@ func WithExecConfig(execFile string, execLine int) ParserConfigOption {
 	}
 }
 
+// WithLogger sets a logger of visited syntax nodes and collected files,
+// and of parsed types if debug is enabled, messages are discarded by default.
+func WithLogger(log debug.Logger) ParserConfigOption {
+	return func(p *Parser) {
+		if log != nil {
+			p.log = log
+		}
+	}
+}
+
+// WithWorkDir sets a working dir of the parser: relative dirs are resolved
+// against it instead of the current directory, and file names and positions
+// of parsed files are relative to it.
+func WithWorkDir(dir string) ParserConfigOption {
+	return func(p *Parser) {
+		p.workDir = dir
+	}
+}
+
+// WithParseCache shares parsed packages between parser runs.
+func WithParseCache(cache *ParseCache) ParserConfigOption {
+	return func(p *Parser) {
+		p.cache = cache
+	}
+}
+
+// WithConcurrency sets the maximum number of directories parsed concurrently,
+// GOMAXPROCS is used if n is not positive.
+func WithConcurrency(n int) ParserConfigOption {
+	return func(p *Parser) {
+		p.concurrency = n
+	}
+}
+
+// WithExclude skips subdirectories matching any of glob patterns,
+// patterns are matched against directory name and its path relative
+// to the parsed directory, e.g. "gen" or "internal/legacy/*".
+func WithExclude(patterns ...string) ParserConfigOption {
+	return func(p *Parser) {
+		p.exclude = append(p.exclude, patterns...)
+	}
+}
+
+// WithSkipNestedModules skips subdirectories with go.mod file,
+// the same way as go tool stops at nested module boundaries.
+func WithSkipNestedModules(skip bool) ParserConfigOption {
+	return func(p *Parser) {
+		p.skipModules = skip
+	}
+}
+
+// WithBuildContext sets build context to filter parsed files by build constraints,
+// it's build.Default by default. If ctx is nil, files of all build constraints
+// are parsed.
+func WithBuildContext(ctx *build.Context) ParserConfigOption {
+	return func(p *Parser) {
+		p.buildCtx = ctx
+	}
+}
+
+// WithDiskCache enables on-disk cache of parsed files.
+func WithDiskCache(cache *DiskCache) ParserConfigOption {
+	return func(p *Parser) {
+		p.diskCache = cache
+	}
+}
+
+// WithWarnings sets an output for warnings about skipped subdirectories,
+// it's os.Stderr by default, warnings are discarded if w is nil.
+func WithWarnings(w io.Writer) ParserConfigOption {
+	return func(p *Parser) {
+		if w == nil {
+			w = io.Discard
+		}
+		p.warn = w
+	}
+}
+
 type Parser struct {
-	fileGlob  string
-	typeGlob  string
-	gogenLine int
-	gogenFile string
-	debug     bool
+	fileGlob    string
+	typeGlob    string
+	gogenLine   int
+	gogenFile   string
+	debug       bool
+	cache       *ParseCache
+	concurrency int
+	exclude     []string
+	skipModules bool
+	buildCtx    *build.Context
+	diskCache   *DiskCache
+	log         debug.Logger
+	workDir     string
+
+	// warn is an output for parse errors of subdirectories
+	warn io.Writer
 }
 
 func NewParser(fileGlob, typeGlob string, opts ...ParserConfigOption) *Parser {
 	p := &Parser{
 		fileGlob: fileGlob,
 		typeGlob: typeGlob,
+		warn:     os.Stderr,
+		buildCtx: &build.Default,
+		log:      debug.NopLogger(),
 	}
 
 	for _, opt := range opts {
@@ -48,11 +149,18 @@ func NewParser(fileGlob, typeGlob string, opts ...ParserConfigOption) *Parser {
 
 func (p *Parser) Parse(dir string) ([]*FileSpec, error) {
 	fset := token.NewFileSet()
+	parse := func(dir string, fset *token.FileSet) (map[string]*ast.Package, error) { //nolint:staticcheck
+		path := p.path(dir)
+		return parsePackages(path, dir, fset, buildFilter(p.buildCtx, path))
+	}
 
-	var colOpts []RootCollectorOption
-	if p.typeGlob == "" {
+	colOpts := []RootCollectorOption{WithCollectorLogger(p.log)}
+	switch {
+	case p.typeGlob == "" && p.gogenFile == "":
+		colOpts = append(colOpts, WithDirectives())
+	case p.typeGlob == "":
 		colOpts = append(colOpts, WithGoGenDecl(p.gogenLine, p.gogenFile))
-	} else {
+	default:
 		m, err := utils.NewGlobMatcher(p.typeGlob)
 		if err != nil {
 			return nil, fmt.Errorf("create type glob matcher: %w", err)
@@ -67,47 +175,308 @@ func (p *Parser) Parse(dir string) ([]*FileSpec, error) {
 		colOpts = append(colOpts, WithFileGlob(m))
 	}
 
+	if p.debug {
+		p.log.Logf("Parsing dir %q (f=%q t=%q)\n", dir, p.fileGlob, p.typeGlob)
+	}
+	// walk through the directory and each subdirectory, parse them concurrently
+	// and collect results in the walk order
+	dirs, err := p.Dirs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root := dir
+	results := parseDirs(dirs, func(dir string) parseResult {
+		return p.parseDir(root, dir, fset, parse, colOpts)
+	}, p.concurrency)
 	col := NewRootCollector(dir, colOpts...)
+	for i, ch := range results {
+		res := <-ch
+		if res.err != nil {
+			// only the parsed directory is required, subdirectories
+			// may contain unrelated broken sources
+			if i == 0 {
+				return nil, fmt.Errorf("failed to parse dir %q: %w", dirs[i], res.err)
+			}
+			fmt.Fprintf(p.warn, "WARNING: skip dir %q: %v\n", dirs[i], res.err)
+		}
+		col.files = append(col.files, res.files...)
+	}
 
 	if p.debug {
-		fmt.Printf("Parsing dir %q (f=%q t=%q)\n", dir, p.fileGlob, p.typeGlob)
+		p.log.Logf("Parsed types:\n")
+		printTraverse(p.log, col.Files(), 0)
+	}
+
+	return col.Files(), nil
+}
+
+// Dirs returns directories parsed by Parse: dir and its subdirectories
+// which are not skipped. Directories are relative to the working dir
+// of the parser, see WithWorkDir.
+func (p *Parser) Dirs(dir string) ([]string, error) {
+	root := p.path(dir)
+	skip, err := p.dirFilter(root)
+	if err != nil {
+		return nil, err
 	}
-	// walk through the directory and each subdirectory and call parseDir for each of them
-	if err := filepath.Walk(dir, parseWalker(fset, col)); err != nil {
+	dirs, err := listDirs(root, skip)
+	if err != nil {
 		return nil, fmt.Errorf("failed to walk through dir: %w", err)
 	}
+	for i, path := range dirs {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk through dir: %w", err)
+		}
+		dirs[i] = filepath.Join(dir, rel)
+	}
+	return dirs, nil
+}
 
-	if p.debug {
-		fmt.Printf("Parsed types:\n")
-		printTraverse(col.Files(), 0)
+// path resolves dir against the working dir of the parser.
+func (p *Parser) path(dir string) string {
+	if p.workDir == "" || filepath.IsAbs(dir) {
+		return dir
 	}
+	return filepath.Join(p.workDir, dir)
+}
 
-	return col.Files(), nil
+// parseDir parses packages of dir and collects its files,
+// files are loaded from disk cache if sources are not changed.
+func (p *Parser) parseDir(root, dir string, fset *token.FileSet, parse parseFunc, colOpts []RootCollectorOption) parseResult {
+	var key string
+	if p.diskCache != nil {
+		var err error
+		path := p.path(dir)
+		key, err = p.diskCache.key(path, p.cacheScope(root), buildFilter(p.buildCtx, path))
+		if err == nil {
+			if files, ok := p.diskCache.load(key); ok {
+				return parseResult{files: files}
+			}
+		} else {
+			key = ""
+		}
+	}
+
+	col := NewRootCollector(root, colOpts...)
+	var err error
+	if p.cache != nil {
+		var files []*FileSpec
+		files, err = p.cache.collectDir(root, dir, p.path(dir), buildContextKey(p.buildCtx), parse, p.log)
+		col.files = col.exportFiles(files)
+	} else {
+		var pkgs map[string]*ast.Package //nolint:staticcheck
+		pkgs, err = parse(dir, fset)
+		walkPackages(pkgs, fset, col, p.log)
+	}
+	if err == nil && key != "" {
+		if err := p.diskCache.store(key, col.files); err != nil {
+			fmt.Fprintf(p.warn, "WARNING: failed to store cache of dir %q: %v\n", dir, err)
+		}
+	}
+	return parseResult{files: col.files, err: err}
+}
+
+// CacheKey identifies files parsed from dir by Parse: all types of these files
+// are the same for parsers of the same dir, build context and subdirectories,
+// only export flags depend on type and file globs.
+func (p *Parser) CacheKey(dir string) (string, error) {
+	abs, err := filepath.Abs(p.path(dir))
+	if err != nil {
+		return "", fmt.Errorf("get absolute path: %w", err)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%q\x00%t\x00%s",
+		abs, p.workDir, dir, p.exclude, p.skipModules, buildContextKey(p.buildCtx)), nil
 }
 
-func parseWalker(fset *token.FileSet, col *RootCollector) filepath.WalkFunc {
-	return func(path string, info fs.FileInfo, err error) error {
+// cacheScope identifies parser options which affect parsed files of root.
+func (p *Parser) cacheScope(root string) string {
+	return fmt.Sprintf("%s\x00%s\x00%q\x00%q\x00%q\x00%d\x00%s",
+		p.workDir, root, p.fileGlob, p.typeGlob, p.gogenFile, p.gogenLine, buildContextKey(p.buildCtx))
+}
+
+// parseFunc parses packages of directory.
+type parseFunc func(dir string, fset *token.FileSet) (map[string]*ast.Package, error) //nolint:staticcheck
+
+// parsePackages parses Go files of directory path like parser.ParseDir,
+// but file names of parsed packages and positions are joined with dir,
+// so they are relative to the working dir of the parser.
+func parsePackages(path, dir string, fset *token.FileSet, filter func(fs.FileInfo) bool) (map[string]*ast.Package, error) { //nolint:staticcheck
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make(map[string]*ast.Package) //nolint:staticcheck
+	var first error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		if filter != nil {
+			info, err := e.Info()
+			if err != nil {
+				return nil, err
+			}
+			if !filter(info) {
+				continue
+			}
+		}
+		src, err := os.ReadFile(filepath.Join(path, e.Name()))
 		if err != nil {
-			return fmt.Errorf("failed to walk through dir: %w", err)
+			return nil, err
 		}
-		if !info.IsDir() {
+		name := filepath.Join(dir, e.Name())
+		f, err := parser.ParseFile(fset, name, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		pkg, ok := pkgs[f.Name.Name]
+		if !ok {
+			pkg = &ast.Package{Name: f.Name.Name, Files: make(map[string]*ast.File)} //nolint:staticcheck
+			pkgs[f.Name.Name] = pkg
+		}
+		pkg.Files[name] = f
+	}
+	return pkgs, first
+}
+
+// SkipDirName checks if directory should be skipped while walking
+// packages recursively: like go tool, it skips vendor and testdata directories
+// and directories starting with '.' or '_', it skips node_modules too.
+func SkipDirName(name string) bool {
+	return name == "vendor" || name == "testdata" || name == "node_modules" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// dirFilter returns a function to check if subdirectory of root should be skipped.
+func (p *Parser) dirFilter(root string) (func(path, name string) bool, error) {
+	exclude := make([]func(string) bool, len(p.exclude))
+	for i, ptn := range p.exclude {
+		m, err := utils.NewGlobMatcher(ptn)
+		if err != nil {
+			return nil, fmt.Errorf("create exclude glob matcher %q: %w", ptn, err)
+		}
+		exclude[i] = m
+	}
+	return func(path, name string) bool {
+		if SkipDirName(name) {
+			return true
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			rel = filepath.ToSlash(rel)
+			for _, m := range exclude {
+				if m(name) || m(rel) {
+					return true
+				}
+			}
+		}
+		if p.skipModules {
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// listDirs returns dir and its subdirectories in lexical order,
+// subdirectories are skipped if skip returns true, dir is never skipped.
+func listDirs(dir string, skip func(path, name string) bool) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
 			return nil
 		}
-		if err := parseDir(path, fset, col); err != nil {
-			return fmt.Errorf("failed to parse dir %q: %w", path, err)
+		if path != dir && skip(path, d.Name()) {
+			return filepath.SkipDir
 		}
+		dirs = append(dirs, path)
 		return nil
+	})
+	return dirs, err
+}
+
+type parseResult struct {
+	files []*FileSpec
+	err   error
+}
+
+// parseDirs parses dirs by a bounded pool of workers, it returns
+// a channel of parse result for each dir in the same order as dirs,
+// so results can be collected in order while other dirs are parsing.
+func parseDirs(dirs []string, parse func(dir string) parseResult, concurrency int) []<-chan parseResult {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	concurrency = min(concurrency, len(dirs))
+
+	results := make([]<-chan parseResult, len(dirs))
+	chans := make([]chan parseResult, len(dirs))
+	for i := range dirs {
+		chans[i] = make(chan parseResult, 1)
+		results[i] = chans[i]
+	}
+	next := make(chan int, len(dirs))
+	for i := range dirs {
+		next <- i
 	}
+	close(next)
+	for range concurrency {
+		go func() {
+			for i := range next {
+				chans[i] <- parse(dirs[i])
+			}
+		}()
+	}
+	return results
 }
 
-func parseDir(dir string, fset *token.FileSet, col *RootCollector) error {
-	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments|parser.SkipObjectResolution)
-	if err != nil {
-		return fmt.Errorf("failed to parse dir: %w", err)
+// walkPackages walks packages of one directory ordered by name,
+// files generated by envdoc are skipped.
+func walkPackages(pkgs map[string]*ast.Package, fset *token.FileSet, col *RootCollector, log debug.Logger) { //nolint:staticcheck
+	names := make([]string, 0, len(pkgs))
+	for name := range pkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		Walk(withoutGenerated(pkgs[name]), fset, col, log)
 	}
+}
 
-	for _, pkg := range pkgs {
-		Walk(pkg, fset, col)
+// withoutGenerated returns package without files generated by envdoc,
+// package is not modified since it may be shared by parse cache.
+func withoutGenerated(pkg *ast.Package) *ast.Package { //nolint:staticcheck
+	var res *ast.Package //nolint:staticcheck
+	for path, f := range pkg.Files {
+		if !isEnvdocGenerated(f) {
+			continue
+		}
+		if res == nil {
+			cp := *pkg
+			cp.Files = maps.Clone(pkg.Files)
+			res = &cp
+		}
+		delete(res.Files, path)
+	}
+	if res == nil {
+		return pkg
 	}
-	return nil
+	return res
+}
+
+// envdocGeneratedHeader is a header comment of Go files generated by envdoc.
+const envdocGeneratedHeader = "// Code generated by envdoc. DO NOT EDIT."
+
+// isEnvdocGenerated checks if file is generated by envdoc with go format,
+// such files are not parsed to not document its own types.
+func isEnvdocGenerated(f *ast.File) bool {
+	return len(f.Comments) > 0 && f.Comments[0].Pos() < f.Package &&
+		f.Comments[0].List[0].Text == envdocGeneratedHeader
 }
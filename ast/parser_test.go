@@ -2,6 +2,8 @@ package ast
 
 import (
 	"fmt"
+	"go/build"
+	"go/token"
 	"os"
 	"path"
 	"path/filepath"
@@ -73,6 +75,7 @@ type parserExpectedField struct {
 	Tag     string                 `yaml:"tag"`
 	TypeRef *parserExpectedTypeRef `yaml:"type_ref"`
 	Fields  []*parserExpectedField `yaml:"fields"`
+	Line    int                    `yaml:"line"`
 }
 
 func (field *parserExpectedField) toAST(t *testing.T) *FieldSpec {
@@ -90,6 +93,7 @@ func (field *parserExpectedField) toAST(t *testing.T) *FieldSpec {
 		Tag:     field.Tag,
 		Fields:  fields,
 		TypeRef: field.TypeRef.toAST(t),
+		Pos:     Position{Line: field.Line},
 	}
 }
 
@@ -114,6 +118,7 @@ type parserExpectedType struct {
 	Exported bool                   `yaml:"export"`
 	Doc      string                 `yaml:"doc"`
 	Fields   []*parserExpectedField `yaml:"fields"`
+	Line     int                    `yaml:"line"`
 }
 
 func (typ *parserExpectedType) toAST(t *testing.T) *TypeSpec {
@@ -127,6 +132,7 @@ func (typ *parserExpectedType) toAST(t *testing.T) *TypeSpec {
 		Name:   typ.Name,
 		Export: typ.Exported,
 		Doc:    typ.Doc,
+		Pos:    Position{Line: typ.Line},
 		Fields: fields,
 	}
 }
@@ -137,6 +143,7 @@ type parserExpectedFile struct {
 	Exported bool                    `yaml:"export"`
 	Imports  []*parsedExpectedImport `yaml:"imports"`
 	Types    []*parserExpectedType   `yaml:"types"`
+	Build    string                  `yaml:"build_constraint"`
 }
 
 func (file *parserExpectedFile) toAST(t *testing.T) *FileSpec {
@@ -157,6 +164,8 @@ func (file *parserExpectedFile) toAST(t *testing.T) *FileSpec {
 		Export:  file.Exported,
 		Imports: imports,
 		Types:   types,
+
+		BuildConstraint: file.Build,
 	}
 }
 
@@ -175,6 +184,10 @@ type parserTestCase struct {
 	FileGlob string `yaml:"file_glob"`
 	TypeGlob string `yaml:"type_glob"`
 	Debug    bool   `yaml:"debug"`
+	GOOS     string `yaml:"goos"`
+	GOARCH   string `yaml:"goarch"`
+	Tags     string `yaml:"tags"`
+	AllTags  bool   `yaml:"all_tags"`
 
 	Expect []*parserExpectedFile `yaml:"files"`
 }
@@ -184,10 +197,26 @@ func testParser(t *testing.T, dir string, tc parserTestCase) {
 
 	var opts []ParserConfigOption
 	if tc.Debug || debug.Config.Enabled {
-		debug.SetTestLogger(t)
 		t.Log("Debug mode")
 		t.Logf("using dir: %s", dir)
-		opts = append(opts, WithDebug(true))
+		opts = append(opts, WithDebug(true), WithLogger(debug.NewTestLogger(t)))
+	}
+	if tc.AllTags {
+		opts = append(opts, WithBuildContext(nil))
+	} else {
+		ctx := build.Default
+		ctx.GOOS = "linux"
+		ctx.GOARCH = "amd64"
+		if tc.GOOS != "" {
+			ctx.GOOS = tc.GOOS
+		}
+		if tc.GOARCH != "" {
+			ctx.GOARCH = tc.GOARCH
+		}
+		if tc.Tags != "" {
+			ctx.BuildTags = strings.Split(tc.Tags, ",")
+		}
+		opts = append(opts, WithBuildContext(&ctx))
 	}
 	p := NewParser(tc.FileGlob, tc.TypeGlob, opts...)
 	files, err := p.Parse(dir)
@@ -228,6 +257,9 @@ func checkFile(t *testing.T, prefix string, expect, res *FileSpec) {
 	if expect.Export != res.Export {
 		t.Errorf("%s: Expected export %t, got %t", prefix, expect.Export, res.Export)
 	}
+	if expect.BuildConstraint != res.BuildConstraint {
+		t.Errorf("%s: Expected build constraint %q, got %q", prefix, expect.BuildConstraint, res.BuildConstraint)
+	}
 	chechImports(t, prefix+"/imports", expect.Imports, res.Imports)
 	checkTypes(t, prefix+"/types", expect.Types, res.Types)
 }
@@ -286,6 +318,9 @@ func checkType(t *testing.T, prefix string, expect, res *TypeSpec) {
 	if expect.Export != res.Export {
 		t.Errorf("%s: Expected export %t, got %t", prefix, expect.Export, res.Export)
 	}
+	if expect.Pos.Line != 0 && expect.Pos.Line != res.Pos.Line {
+		t.Errorf("%s: Expected line %d, got %d", prefix, expect.Pos.Line, res.Pos.Line)
+	}
 	checkFields(t, prefix+"/fields", expect.Fields, res.Fields)
 }
 
@@ -335,6 +370,9 @@ func checkField(t *testing.T, prefix string, expect, res *FieldSpec) {
 	if expect.Tag != res.Tag {
 		t.Errorf("%s: Expected tag %q, got %q", prefix, expect.Tag, res.Tag)
 	}
+	if expect.Pos.Line != 0 && expect.Pos.Line != res.Pos.Line {
+		t.Errorf("%s: Expected line %d, got %d", prefix, expect.Pos.Line, res.Pos.Line)
+	}
 
 	checkTypeRef(t, prefix+"/typeref", &expect.TypeRef, &res.TypeRef)
 	checkFields(t, prefix+"/fields", expect.Fields, res.Fields)
@@ -383,3 +421,226 @@ func readTestCase(t *testing.T, dir string) parserTestCase {
 	}
 	return tmp.TestCase
 }
+
+// writeSyntheticTree writes dirs packages with files source files of envdoc types each.
+func writeSyntheticTree(tb testing.TB, root string, dirs, files int) {
+	tb.Helper()
+	for d := range dirs {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%03d", d/10), fmt.Sprintf("sub%03d", d))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			tb.Fatalf("mkdir: %v", err)
+		}
+		for f := range files {
+			var src strings.Builder
+			fmt.Fprintf(&src, "package sub%03d\n\n", d)
+			fmt.Fprintf(&src, "// Config%d doc.\ntype Config%d struct {\n", f, f)
+			for i := range 10 {
+				fmt.Fprintf(&src, "\t// Field%d doc.\n\tField%d string `env:\"FIELD_%d\" envDefault:\"%d\"`\n", i, i, i, i)
+			}
+			fmt.Fprintf(&src, "\tNested struct {\n\t\t// Inner doc.\n\t\tInner int `env:\"INNER\"`\n\t} `envPrefix:\"NESTED_\"`\n}\n")
+			name := filepath.Join(dir, fmt.Sprintf("config%d.go", f))
+			if err := os.WriteFile(name, []byte(src.String()), 0o600); err != nil {
+				tb.Fatalf("write file: %v", err)
+			}
+		}
+	}
+}
+
+func TestParserConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	writeSyntheticTree(t, dir, 30, 3)
+
+	expect, err := NewParser("*", "*", WithConcurrency(1)).Parse(dir)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(expect) != 90 {
+		t.Fatalf("unexpected files count: %d", len(expect))
+	}
+	for _, n := range []int{2, 8, 0} {
+		t.Run(fmt.Sprintf("concurrency=%d", n), func(t *testing.T) {
+			files, err := NewParser("*", "*", WithConcurrency(n)).Parse(dir)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			checkFiles(t, "", expect, files)
+		})
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	writeBad := func(t *testing.T, dir string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "bad.go"), []byte("package bad\n\ntype {\n"), 0o600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	t.Run("subdir", func(t *testing.T) {
+		dir := t.TempDir()
+		writeSyntheticTree(t, dir, 5, 1)
+		writeBad(t, filepath.Join(dir, "pkg000", "sub002"))
+		var warn strings.Builder
+		p := NewParser("*", "*", WithConcurrency(4))
+		p.warn = &warn
+		files, err := p.Parse(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 5 {
+			t.Errorf("unexpected files count: %d", len(files))
+		}
+		if !strings.Contains(warn.String(), "WARNING: skip dir") || !strings.Contains(warn.String(), "sub002") {
+			t.Errorf("expected warning for sub002, got: %q", warn.String())
+		}
+	})
+	t.Run("root", func(t *testing.T) {
+		dir := t.TempDir()
+		writeBad(t, dir)
+		_, err := NewParser("*", "*").Parse(dir)
+		if err == nil {
+			t.Fatal("expected parse error of root dir")
+		}
+	})
+}
+
+func TestParserSkipDirs(t *testing.T) {
+	dir := t.TempDir()
+	writeSyntheticTree(t, dir, 3, 1)
+	for _, name := range []string{"vendor", "testdata", ".git", "_examples", "node_modules", "gen", "pkg000/sub001/legacy"} {
+		writeSyntheticTree(t, filepath.Join(dir, name), 1, 1)
+	}
+	module := filepath.Join(dir, "tools")
+	writeSyntheticTree(t, module, 1, 1)
+	if err := os.WriteFile(filepath.Join(module, "go.mod"), []byte("module tools\n"), 0o600); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		opts   []ParserConfigOption
+		expect int
+	}{
+		{"default", nil, 6},
+		{"exclude", []ParserConfigOption{WithExclude("gen", "pkg000/*/legacy")}, 4},
+		{"nested modules", []ParserConfigOption{WithSkipNestedModules(true)}, 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := NewParser("*", "*", tc.opts...).Parse(dir)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if len(files) != tc.expect {
+				for _, f := range files {
+					t.Logf("file: %s", f.Name)
+				}
+				t.Fatalf("expected %d files, got %d", tc.expect, len(files))
+			}
+		})
+	}
+	t.Run("root is never skipped", func(t *testing.T) {
+		files, err := NewParser("*", "*").Parse(filepath.Join(dir, "testdata"))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("expected 1 file, got %d", len(files))
+		}
+	})
+}
+
+func TestParserSkipGenerated(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"config.go":  "package config\n\ntype Config struct {\n\tPort int `env:\"PORT\"`\n}\n",
+		"env_gen.go": "// Code generated by envdoc. DO NOT EDIT.\n\npackage config\n\ntype EnvVarDoc struct {\n\tName string\n}\n",
+		"other.go":   "// Code generated by stringer. DO NOT EDIT.\n\npackage config\n\ntype Level int\n",
+	}
+	for name, src := range sources {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	files, err := NewParser("*", "*").Parse(dir)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "./config.go,./other.go" {
+		t.Fatalf("unexpected files: %v", names)
+	}
+
+	// parsed packages may be shared by parse cache, so they are not modified
+	pkgs, err := parsePackages(dir, dir, token.NewFileSet(), nil)
+	if err != nil {
+		t.Fatalf("parse packages: %v", err)
+	}
+	pkg := pkgs["config"]
+	if res := withoutGenerated(pkg); len(res.Files) != 2 || len(pkg.Files) != 3 {
+		t.Fatalf("unexpected files: %d filtered, %d parsed", len(res.Files), len(pkg.Files))
+	}
+}
+
+func TestParserWorkDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	sources := map[string]string{
+		"config.go":     "package config\n\n//go:generate envdoc\ntype Config struct {\n\tPort int `env:\"PORT\"`\n}\n",
+		"sub/nested.go": "package sub\n\ntype Nested struct {\n\tHost string `env:\"HOST\"`\n}\n",
+	}
+	for name, src := range sources {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	// paths are relative to the working dir, the same as parsing
+	// in that directory, and the current directory is not changed
+	for _, opts := range [][]ParserConfigOption{
+		{WithWorkDir(dir)},
+		{WithWorkDir(dir), WithParseCache(NewParseCache())},
+	} {
+		p := NewParser("config.go", "", append(opts, WithExecConfig("config.go", 3))...)
+		dirs, err := p.Dirs(".")
+		if err != nil {
+			t.Fatalf("dirs: %v", err)
+		}
+		if strings.Join(dirs, ",") != ".,sub" {
+			t.Fatalf("unexpected dirs: %v", dirs)
+		}
+		files, err := p.Parse(".")
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if len(files) != 2 || files[0].Name != "config.go" || !files[0].Export || files[1].Name != "sub/nested.go" {
+			t.Fatalf("unexpected files: %+v", files)
+		}
+		if len(files[0].Types) != 1 || !files[0].Types[0].Export {
+			t.Fatalf("unexpected types: %+v", files[0].Types)
+		}
+		if pos := files[0].Types[0].Fields[0].Pos; pos.File != "config.go" || pos.Line != 5 {
+			t.Fatalf("unexpected position: %s", pos)
+		}
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	dir := b.TempDir()
+	writeSyntheticTree(b, dir, 200, 5)
+	for _, n := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("concurrency=%d", n), func(b *testing.B) {
+			p := NewParser("*", "*", WithConcurrency(n))
+			for b.Loop() {
+				if _, err := p.Parse(dir); err != nil {
+					b.Fatalf("parse: %v", err)
+				}
+			}
+		})
+	}
+}
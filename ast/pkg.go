@@ -4,25 +4,29 @@ import (
 	"go/ast"
 	"go/doc"
 	"go/token"
+
+	"github.com/g4s8/envdoc/debug"
 )
 
 type pkgVisitor struct {
 	fset *token.FileSet
 	h    FileHandler
+	log  debug.Logger
 
 	pkg  string
 	docs *doc.Package
 }
 
-func newPkgVisitor(fset *token.FileSet, h FileHandler) *pkgVisitor {
+func newPkgVisitor(fset *token.FileSet, h FileHandler, log debug.Logger) *pkgVisitor {
 	return &pkgVisitor{
 		fset: fset,
 		h:    h,
+		log:  log,
 	}
 }
 
 func (p *pkgVisitor) Visit(n ast.Node) ast.Visitor {
-	debugNode("pkg", n)
+	debugNode(p.log, "pkg", n)
 	switch t := n.(type) {
 	//nolint:staticcheck
 	case *ast.Package:
@@ -32,10 +36,11 @@ func (p *pkgVisitor) Visit(n ast.Node) ast.Visitor {
 	case *ast.File:
 		f := p.fset.File(t.Pos())
 		if fa := p.h.onFile(&FileSpec{
-			Name: f.Name(),
-			Pkg:  p.pkg,
+			Name:            f.Name(),
+			Pkg:             p.pkg,
+			BuildConstraint: buildConstraint(f.Name(), t),
 		}); fa != nil {
-			return newFileVisitor(p.fset, t, p.docs, fa)
+			return newFileVisitor(p.fset, t, p.docs, fa, p.log)
 		}
 	}
 	return p
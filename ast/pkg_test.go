@@ -5,12 +5,14 @@ import (
 	"path/filepath"
 	"slices"
 	"testing"
+
+	"github.com/g4s8/envdoc/debug"
 )
 
 func TestPkgVisitor(t *testing.T) {
 	fset, pkg, _ := loadTestFileSet(t)
 	h := &testFileHandler{}
-	v := newPkgVisitor(fset, h)
+	v := newPkgVisitor(fset, h, debug.NopLogger())
 	ast.Walk(v, pkg)
 	if len(h.files) != 4 {
 		t.Fatalf("expected 4 files, got %d", len(h.files))
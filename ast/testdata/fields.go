@@ -8,4 +8,5 @@ type Fields struct {
 	A int
 	B string
 	C bool // c-field
+	D map[int]bool
 }
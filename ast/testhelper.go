@@ -5,6 +5,8 @@ import (
 	"go/doc"
 	"go/parser"
 	"go/token"
+
+	"github.com/g4s8/envdoc/debug"
 )
 
 type T interface {
@@ -114,6 +116,6 @@ func testFileVisitor(fset *token.FileSet, pkg *ast.Package, fileName string,
 	}
 	fh := &testFileHandler{}
 	th := fh.onFile(fileSpec)
-	fv := newFileVisitor(fset, fileAst, docs, th)
+	fv := newFileVisitor(fset, fileAst, docs, th, debug.NopLogger())
 	return fh, fv, fileAst
 }
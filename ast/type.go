@@ -2,6 +2,9 @@ package ast
 
 import (
 	"go/ast"
+	"go/token"
+
+	"github.com/g4s8/envdoc/debug"
 )
 
 type typeVisitorHandler = interface {
@@ -10,16 +13,18 @@ type typeVisitorHandler = interface {
 }
 
 type typeVisitor struct {
-	pkg string
-	h   typeVisitorHandler
+	fset *token.FileSet
+	pkg  string
+	h    typeVisitorHandler
+	log  debug.Logger
 }
 
-func newTypeVisitor(pkg string, h typeVisitorHandler) *typeVisitor {
-	return &typeVisitor{pkg: pkg, h: h}
+func newTypeVisitor(fset *token.FileSet, pkg string, h typeVisitorHandler, log debug.Logger) *typeVisitor {
+	return &typeVisitor{fset: fset, pkg: pkg, h: h, log: log}
 }
 
 func (v *typeVisitor) Visit(n ast.Node) ast.Visitor {
-	debugNode("type", n)
+	debugNode(v.log, "type", n)
 	switch t := n.(type) {
 	case *ast.Comment:
 		v.h.setComment(&CommentSpec{
@@ -27,12 +32,12 @@ func (v *typeVisitor) Visit(n ast.Node) ast.Visitor {
 		})
 		return nil
 	case *ast.Field:
-		fs := getFieldSpec(t, v.pkg)
+		fs := getFieldSpec(v.fset, t, v.pkg)
 		if fs == nil {
 			return nil
 		}
 		if fa := v.h.onField(fs); fa != nil {
-			return newFieldVisitor(v.pkg, fa)
+			return newFieldVisitor(v.fset, v.pkg, fa, v.log)
 		}
 		return nil
 	}
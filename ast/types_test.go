@@ -4,6 +4,8 @@ import (
 	"go/ast"
 	"path/filepath"
 	"testing"
+
+	"github.com/g4s8/envdoc/debug"
 )
 
 type fileHandler struct {
@@ -43,12 +45,12 @@ func TestTypesVisitor(t *testing.T) {
 	fset, pkg, docs := loadTestFileSet(t)
 	file := pkg.Files[filepath.Join("testdata", "fields.go")]
 	h := &fileHandler{}
-	v := newFileVisitor(fset, file, docs, h)
+	v := newFileVisitor(fset, file, docs, h, debug.NopLogger())
 
 	ast.Walk(v, file)
 
 	fh := h.typeH
-	if expect, actual := 3, len(fh.fields); expect != actual {
+	if expect, actual := 4, len(fh.fields); expect != actual {
 		t.Fatalf("expected %d fields, got %d", expect, actual)
 	}
 	if expect, actual := "A", fh.fields[0].Names[0]; expect != actual {
@@ -60,4 +62,7 @@ func TestTypesVisitor(t *testing.T) {
 	if expect, actual := "C", fh.fields[2].Names[0]; expect != actual {
 		t.Fatalf("expected field name %q, got %q", expect, actual)
 	}
+	if expect, actual := "map[int]bool", fh.fields[3].TypeRef.String(); expect != actual {
+		t.Fatalf("expected field type %q, got %q", expect, actual)
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"go/ast"
 	"go/doc"
 	"go/token"
+	"go/types"
 	"strings"
 
 	"github.com/g4s8/envdoc/debug"
@@ -28,6 +29,7 @@ func getFieldTypeRef(f ast.Expr, ref *FieldTypeRef) bool {
 	case *ast.MapType:
 		getFieldTypeRef(t.Value, ref)
 		ref.Kind = FieldTypeMap
+		ref.Key = types.ExprString(t.Key)
 	case *ast.StructType:
 		ref.Kind = FieldTypeStruct
 	default:
@@ -53,17 +55,18 @@ func extractFieldDoc(f *ast.Field) (doc string, ok bool) {
 	return doc, doc != ""
 }
 
-func findCommentLine(c *ast.Comment, fset *token.FileSet, file *ast.File) int {
-	lines := fset.File(file.Pos()).Lines()
-	for l, pos := range lines {
-		if token.Pos(pos) > c.Pos() {
-			return l
-		}
-	}
-	return 0
+// findCommentLine returns line number of comment, file set may contain
+// other files, so comment position is not the same as offset in the file.
+func findCommentLine(c *ast.Comment, fset *token.FileSet) int {
+	return fset.Position(c.Pos()).Line
+}
+
+func getPosition(fset *token.FileSet, pos token.Pos) Position {
+	p := fset.Position(pos)
+	return Position{File: p.Filename, Line: p.Line}
 }
 
-func getFieldSpec(n *ast.Field, pkg string) *FieldSpec {
+func getFieldSpec(fset *token.FileSet, n *ast.Field, pkg string) *FieldSpec {
 	names := extractFieldNames(n)
 	allPrivate := true
 	for _, name := range names {
@@ -79,6 +82,7 @@ func getFieldSpec(n *ast.Field, pkg string) *FieldSpec {
 
 	var fs FieldSpec
 	fs.Names = names
+	fs.Pos = getPosition(fset, n.Pos())
 	if !getFieldTypeRef(n.Type, &fs.TypeRef) {
 		// unsupported field type
 		return nil
@@ -97,35 +101,32 @@ func getFieldSpec(n *ast.Field, pkg string) *FieldSpec {
 }
 
 //nolint:cyclop
-func debugNode(src string, n ast.Node) {
-	if !debug.Config.Enabled {
-		return
-	}
+func debugNode(log debug.Logger, src string, n ast.Node) {
 	if n == nil {
 		return
 	}
 
 	switch t := n.(type) {
 	case *ast.File:
-		debug.Logf("# AST(%s): File pkg=%q\n", src, t.Name.Name)
+		log.Logf("# AST(%s): File pkg=%q\n", src, t.Name.Name)
 	case *ast.ImportSpec:
-		debug.Logf("# AST(%s): Import %s %s\n", src, t.Name, t.Path.Value)
+		log.Logf("# AST(%s): Import %s %s\n", src, t.Name, t.Path.Value)
 	//nolint:staticcheck
 	case *ast.Package:
-		debug.Logf("# AST(%s): Package %s\n", src, t.Name)
+		log.Logf("# AST(%s): Package %s\n", src, t.Name)
 	case *ast.TypeSpec:
-		debug.Logf("# AST(%s): Type %s\n", src, t.Name.Name)
+		log.Logf("# AST(%s): Type %s\n", src, t.Name.Name)
 	case *ast.Field:
 		names := extractFieldNames(t)
-		debug.Logf("# AST(%s): Field %s\n", src, strings.Join(names, ", "))
+		log.Logf("# AST(%s): Field %s\n", src, strings.Join(names, ", "))
 	case *ast.Comment:
-		debug.Logf("# AST(%s): Comment %s\n", src, t.Text)
+		log.Logf("# AST(%s): Comment %s\n", src, t.Text)
 	case *ast.StructType:
-		debug.Logf("# AST(%s): Struct\n", src)
+		log.Logf("# AST(%s): Struct\n", src)
 	case *ast.GenDecl, *ast.Ident, *ast.FuncDecl:
 		// ignore
 	default:
-		debug.Logf("# AST(%s): %T\n", src, t)
+		log.Logf("# AST(%s): %T\n", src, t)
 	}
 }
 
@@ -3,9 +3,13 @@ package ast
 import (
 	"go/ast"
 	"go/token"
+
+	"github.com/g4s8/envdoc/debug"
 )
 
-func Walk(n ast.Node, fset *token.FileSet, h FileHandler) {
-	v := newPkgVisitor(fset, h)
+// Walk visits package node n and emits its files, types and fields to h,
+// visited nodes are logged to log.
+func Walk(n ast.Node, fset *token.FileSet, h FileHandler, log debug.Logger) {
+	v := newPkgVisitor(fset, h, log)
 	ast.Walk(v, n)
 }
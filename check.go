@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/g4s8/envdoc/utils"
+)
+
+var ErrOutdated = errors.New("output is out of date")
+
+// checkOutput compares current content of output file with generated one,
+// it writes unified diff to out and returns ErrOutdated if they differ.
+func checkOutput(file string, current, generated []byte, out io.Writer) error {
+	if bytes.Equal(current, generated) {
+		return nil
+	}
+	diff := utils.UnifiedDiff(file, file+" (generated)", string(current), string(generated))
+	if _, err := io.WriteString(out, diff); err != nil {
+		return fmt.Errorf("write diff: %w", err)
+	}
+	return fmt.Errorf("%s: %w", file, ErrOutdated)
+}
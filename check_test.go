@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckOutput(t *testing.T) {
+	t.Run("up to date", func(t *testing.T) {
+		var out strings.Builder
+		if err := checkOutput("doc.md", []byte("a\n"), []byte("a\n"), &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.Len() != 0 {
+			t.Fatalf("unexpected diff: %s", out.String())
+		}
+	})
+	t.Run("outdated", func(t *testing.T) {
+		var out strings.Builder
+		err := checkOutput("doc.md", []byte("a\nb\n"), []byte("a\nc\n"), &out)
+		if !errors.Is(err, ErrOutdated) {
+			t.Fatalf("expected ErrOutdated, got: %v", err)
+		}
+		expect := "--- doc.md\n+++ doc.md (generated)\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"
+		if out.String() != expect {
+			t.Fatalf("unexpected diff:\n%s\nexpected:\n%s", out.String(), expect)
+		}
+	})
+	t.Run("missing file", func(t *testing.T) {
+		var out strings.Builder
+		err := checkOutput("doc.md", nil, []byte("a\n"), &out)
+		if !errors.Is(err, ErrOutdated) {
+			t.Fatalf("expected ErrOutdated, got: %v", err)
+		}
+	})
+}
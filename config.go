@@ -4,11 +4,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"go/build"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/g4s8/envdoc/edit"
+	"github.com/g4s8/envdoc/render"
 	"github.com/g4s8/envdoc/types"
 	"github.com/g4s8/envdoc/utils"
 )
@@ -20,16 +25,60 @@ type Config struct {
 	FileGlob string
 	// TypeGlob to filter by type name
 	TypeGlob string
+	// Concurrency is the maximum number of directories parsed concurrently,
+	// GOMAXPROCS by default
+	Concurrency int
+	// Exclude is a comma-separated list of globs of skipped subdirectories
+	Exclude string
+	// SkipNestedModules skips subdirectories with go.mod file
+	SkipNestedModules bool
+	// BuildTags is a comma-separated list of build tags to match files
+	BuildTags string
+	// GOOS and GOARCH to match files, current GOOS and GOARCH by default
+	GOOS, GOARCH string
+	// AllTags parses files of all build constraints and adds
+	// build constraint note to variables
+	AllTags bool
+	// NoCache disables on-disk cache of parsed files
+	NoCache bool
+	// Watch keeps running and regenerates outputs on source changes
+	Watch bool
+	// WatchInterval is a polling interval of watch mode
+	WatchInterval time.Duration
 	// OutFile to write the output to
 	OutFile string
+	// Outputs are multiple outputs of fmt=path form
+	Outputs []OutputSpec
 	// OutFormat specify the output format
 	OutFormat types.OutFormat
 	// EnvPrefix to prefix the env vars with
 	EnvPrefix string
 	// NoStyles to disable styles for HTML format
 	NoStyles bool
+	// ComposeService is a service name for compose format
+	ComposeService string
+	// ManSectionOnly renders only ENVIRONMENT section for man format
+	ManSectionOnly bool
+	// GoPackage is a package name for go format, package of documented types by default
+	GoPackage string
+	// TemplateFile is a custom template file path
+	TemplateFile string
+	// SourceLinkTemplate is a template of links to variable declarations
+	// with {file} and {line} placeholders
+	SourceLinkTemplate string
 	// Edit enables in-place editing mode (replaces content between markers)
 	Edit bool
+	// MarkerStyle overrides marker style for edit mode
+	MarkerStyle string
+	// Section is a name of edited section for edit mode
+	Section string
+	// Check mode compares generated output with output file without writing it
+	Check bool
+	// ConfigFile is a path to config file, by default .envdoc.yaml is searched
+	// in Dir and its parents
+	ConfigFile string
+	// Profile is a name of config file profile to generate
+	Profile string
 	// FieldNames flag enables field names usage intead of `env` tag.
 	FieldNames bool
 	// Target is the target type
@@ -46,24 +95,52 @@ type Config struct {
 	ExecLine int
 	// ExecFile is the file of go:generate command
 	ExecFile string
+	// WorkDir is a directory of go:generate command if it's not the current
+	// directory: relative paths are resolved against it
+	WorkDir string
 
 	// Debug output enabled
 	Debug bool
+
+	// flags are names of explicitly set flags, they override config file options
+	flags map[string]bool
 }
 
 //nolint:cyclop
-func (c *Config) parseFlags(f *flag.FlagSet) error {
+func (c *Config) parseFlags(f *flag.FlagSet, args []string) error {
 	// input flags
 	f.StringVar(&c.Dir, "dir", "", "Dir to search for files, default is the file dir with go:generate command")
 	f.StringVar(&c.FileGlob, "files", "", "FileGlob to filter by file name")
 	f.StringVar(&c.TypeGlob, "types", "", "Type glob to filter by type name")
+	f.IntVar(&c.Concurrency, "concurrency", 0, "Maximum number of directories parsed concurrently, default is GOMAXPROCS")
+	f.StringVar(&c.Exclude, "exclude", "", "Comma-separated globs of subdirectories to skip")
+	f.BoolVar(&c.SkipNestedModules, "skip-nested-modules", false, "Skip subdirectories with go.mod file")
+	f.StringVar(&c.BuildTags, "tags", "", "Comma-separated list of build tags to match files")
+	f.StringVar(&c.GOOS, "goos", "", "GOOS to match files, default is the current GOOS")
+	f.StringVar(&c.GOARCH, "goarch", "", "GOARCH to match files, default is the current GOARCH")
+	f.BoolVar(&c.AllTags, "all-tags", false, "Parse files of all build constraints and add build constraint notes")
+	f.BoolVar(&c.NoCache, "no-cache", false, "Disable on-disk cache of parsed files")
+	f.BoolVar(&c.Watch, "watch", false, "Watch source files and regenerate outputs on change")
+	f.DurationVar(&c.WatchInterval, "watch-interval", DefaultWatchInterval, "Polling interval of watch mode")
 	var target string
 	f.StringVar(&target, "target", "caarlos0", "Target type, default `caarlos0`")
 	// output flags
-	f.StringVar(&c.OutFile, "output", "", "Output file path")
+	f.Var(outputFlag{c}, "output", "Output file path, or fmt=path (fmt:edit=path) pair, may be repeated for multiple outputs")
 	f.StringVar((*string)(&c.OutFormat), "format", "markdown", "Output format, default `markdown`")
 	f.BoolVar(&c.NoStyles, "no-styles", false, "Disable styles for HTML output")
+	f.StringVar(&c.ComposeService, "compose-service", render.DefaultComposeService, "Service name for compose output")
+	f.BoolVar(&c.ManSectionOnly, "man-section-only", false, "Render only ENVIRONMENT section for man output")
+	f.StringVar(&c.GoPackage, "go-package", "", "Package name for go output, default is the package of documented types")
+	f.StringVar(&c.TemplateFile, "template", "", "Custom template file path")
+	f.StringVar(&c.SourceLinkTemplate, "source-link-template", "",
+		"Template of links to variable declarations with {file} and {line} placeholders")
 	f.BoolVar(&c.Edit, "edit", false, "Enable in-place editing mode (replaces content between markers)")
+	f.StringVar(&c.MarkerStyle, "marker-style", "", "Marker style for edit mode: html, hash, slash, roff or rst")
+	f.StringVar(&c.Section, "section", "", "Section name for edit mode (envdoc:begin:NAME markers)")
+	f.BoolVar(&c.Check, "check", false, "Check that output file is up to date without writing it")
+	// config file flags
+	f.StringVar(&c.ConfigFile, "config", "", "Config file path, default is "+ConfigFileName+" in dir or its parents")
+	f.StringVar(&c.Profile, "profile", "", "Config file profile name to generate")
 	// app config flags
 	f.StringVar(&c.EnvPrefix, "env-prefix", "", "Environment variable prefix")
 	f.BoolVar(&c.FieldNames, "field-names", false, "Use field names if tag is not specified")
@@ -81,9 +158,16 @@ func (c *Config) parseFlags(f *flag.FlagSet) error {
 	f.BoolVar(&all, "all", false, "Generate documentation for all types in the file (deprecated: use -types='*' instead)")
 
 	// parse
-	if err := f.Parse(os.Args[1:]); err != nil {
+	if err := f.Parse(args); err != nil {
 		return fmt.Errorf("parse flags: %w", err)
 	}
+	c.flags = make(map[string]bool)
+	f.Visit(func(fl *flag.Flag) {
+		c.flags[fl.Name] = true
+	})
+	if all || typeName != "" {
+		c.flags["types"] = true
+	}
 
 	// deprecated flags `all`, `type` and new flag `types` can't be used together
 	if all && typeName != "" {
@@ -122,26 +206,30 @@ func (c *Config) parseFlags(f *flag.FlagSet) error {
 
 var ErrNotCalledByGoGenerate = errors.New("not called by go generate")
 
+// parseEnv reads go generate environment. If envdoc is not called by go generate,
+// it's running in standalone mode: ExecFile is empty and types are selected
+// by -types flag or by envdoc go:generate directives.
 func (c *Config) parseEnv() error {
-	inputFileName := os.Getenv("GOFILE")
-	if inputFileName == "" {
-		return fmt.Errorf("no exec input file specified: %w", ErrNotCalledByGoGenerate)
-	}
-	c.ExecFile = inputFileName
+	if inputFileName := os.Getenv("GOFILE"); inputFileName != "" {
+		c.ExecFile = inputFileName
 
-	if e := os.Getenv("GOLINE"); e != "" {
-		i, err := strconv.Atoi(e)
-		if err != nil {
-			return fmt.Errorf("invalid exec line number specified: %w", err)
+		if e := os.Getenv("GOLINE"); e != "" {
+			i, err := strconv.Atoi(e)
+			if err != nil {
+				return fmt.Errorf("invalid exec line number specified: %w", err)
+			}
+			c.ExecLine = i
+		} else {
+			return fmt.Errorf("no exec line number specified: %w", ErrNotCalledByGoGenerate)
 		}
-		c.ExecLine = i
-	} else {
-		return fmt.Errorf("no exec line number specified: %w", ErrNotCalledByGoGenerate)
 	}
 
 	if e := os.Getenv("DEBUG"); e != "" {
 		c.Debug = true
 	}
+	if e := os.Getenv("ENVDOC_CHECK"); e != "" {
+		c.Check = true
+	}
 
 	return nil
 }
@@ -149,6 +237,56 @@ func (c *Config) parseEnv() error {
 func (c *Config) normalize() {
 	c.TypeGlob = utils.UnescapeGlob(c.TypeGlob)
 	c.FileGlob = utils.UnescapeGlob(c.FileGlob)
+	c.Dir = packageDir(c.Dir)
+}
+
+// packageDir converts package pattern like ./... to directory,
+// subdirectories are always parsed recursively.
+func packageDir(pattern string) string {
+	if pattern == "..." {
+		return "."
+	}
+	if dir, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return dir
+	}
+	return pattern
+}
+
+// isStdout checks if output file is standard output: "-" or empty.
+func isStdout(file string) bool {
+	return file == "" || file == "-"
+}
+
+// excludes returns glob patterns of -exclude flag.
+func (c Config) excludes() []string {
+	var res []string
+	for _, ptn := range strings.Split(c.Exclude, ",") {
+		if ptn = strings.TrimSpace(ptn); ptn != "" {
+			res = append(res, utils.UnescapeGlob(ptn))
+		}
+	}
+	return res
+}
+
+// buildContext returns build context to match parsed files,
+// it returns nil in all tags mode.
+func (c Config) buildContext() *build.Context {
+	if c.AllTags {
+		return nil
+	}
+	ctx := build.Default
+	if c.GOOS != "" {
+		ctx.GOOS = c.GOOS
+	}
+	if c.GOARCH != "" {
+		ctx.GOARCH = c.GOARCH
+	}
+	for _, tag := range strings.Split(c.BuildTags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ctx.BuildTags = append(ctx.BuildTags, tag)
+		}
+	}
+	return &ctx
 }
 
 func (c *Config) setDefaults() {
@@ -169,7 +307,12 @@ func (c *Config) fprint(out io.Writer) {
 	if c.TypeGlob != "" {
 		fmt.Fprintf(out, "  TypeGlob: %q\n", c.TypeGlob)
 	}
-	fmt.Fprintf(out, "  OutFile: %q\n", c.OutFile)
+	if c.OutFile != "" || len(c.Outputs) == 0 {
+		fmt.Fprintf(out, "  OutFile: %q\n", c.OutFile)
+	}
+	for _, o := range c.Outputs {
+		fmt.Fprintf(out, "  Output: %q\n", o)
+	}
 	fmt.Fprintf(out, "  OutFormat: %q\n", c.OutFormat)
 	if c.EnvPrefix != "" {
 		fmt.Fprintf(out, "  EnvPrefix: %q\n", c.EnvPrefix)
@@ -177,11 +320,68 @@ func (c *Config) fprint(out io.Writer) {
 	if c.NoStyles {
 		fmt.Fprintln(out, "  NoStyles: true")
 	}
+	if c.OutFormat == types.OutFormatCompose {
+		fmt.Fprintf(out, "  ComposeService: %q\n", c.ComposeService)
+	}
+	if c.ManSectionOnly {
+		fmt.Fprintln(out, "  ManSectionOnly: true")
+	}
+	if c.GoPackage != "" {
+		fmt.Fprintf(out, "  GoPackage: %q\n", c.GoPackage)
+	}
+	if c.TemplateFile != "" {
+		fmt.Fprintf(out, "  TemplateFile: %q\n", c.TemplateFile)
+	}
+	if c.SourceLinkTemplate != "" {
+		fmt.Fprintf(out, "  SourceLinkTemplate: %q\n", c.SourceLinkTemplate)
+	}
 	if c.Edit {
 		fmt.Fprintln(out, "  Edit: true")
 	}
-	fmt.Printf("  ExecFile: %q\n", c.ExecFile)
-	fmt.Printf("  ExecLine: %d\n", c.ExecLine)
+	if c.MarkerStyle != "" {
+		fmt.Fprintf(out, "  MarkerStyle: %q\n", c.MarkerStyle)
+	}
+	if c.Section != "" {
+		fmt.Fprintf(out, "  Section: %q\n", c.Section)
+	}
+	if c.Check {
+		fmt.Fprintln(out, "  Check: true")
+	}
+	if c.ConfigFile != "" {
+		fmt.Fprintf(out, "  ConfigFile: %q\n", c.ConfigFile)
+	}
+	if c.Profile != "" {
+		fmt.Fprintf(out, "  Profile: %q\n", c.Profile)
+	}
+	fmt.Fprintf(out, "  ExecFile: %q\n", c.ExecFile)
+	fmt.Fprintf(out, "  ExecLine: %d\n", c.ExecLine)
+	if c.WorkDir != "" {
+		fmt.Fprintf(out, "  WorkDir: %q\n", c.WorkDir)
+	}
+	if c.Concurrency > 0 {
+		fmt.Fprintf(out, "  Concurrency: %d\n", c.Concurrency)
+	}
+	if c.Exclude != "" {
+		fmt.Fprintf(out, "  Exclude: %q\n", c.Exclude)
+	}
+	if c.SkipNestedModules {
+		fmt.Fprintln(out, "  SkipNestedModules: true")
+	}
+	if c.BuildTags != "" {
+		fmt.Fprintf(out, "  BuildTags: %q\n", c.BuildTags)
+	}
+	if c.GOOS != "" || c.GOARCH != "" {
+		fmt.Fprintf(out, "  GOOS/GOARCH: %q/%q\n", c.GOOS, c.GOARCH)
+	}
+	if c.AllTags {
+		fmt.Fprintln(out, "  AllTags: true")
+	}
+	if c.NoCache {
+		fmt.Fprintln(out, "  NoCache: true")
+	}
+	if c.Watch {
+		fmt.Fprintf(out, "  Watch: %s\n", c.WatchInterval)
+	}
 	if c.FieldNames {
 		fmt.Fprintln(out, "  FieldNames: true")
 	}
@@ -190,26 +390,192 @@ func (c *Config) fprint(out io.Writer) {
 	}
 }
 
-func (c *Config) Load() error {
+// Load parses flags and environment into c, then applies config file options.
+// It returns a config for each output profile of config file,
+// or c itself if config file doesn't have profiles.
+func (c *Config) Load() ([]Config, error) {
 	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
-	if err := c.parseFlags(fs); err != nil {
-		return fmt.Errorf("parse flags: %w", err)
+	if err := c.parseFlags(fs, os.Args[1:]); err != nil {
+		return nil, fmt.Errorf("parse flags: %w", err)
 	}
 	if err := c.parseEnv(); err != nil {
-		return fmt.Errorf("parse env: %w", err)
+		return nil, fmt.Errorf("parse env: %w", err)
 	}
-	c.setDefaults()
-	c.normalize()
-	return nil
+	return c.configs()
+}
+
+// commandConfig reads environment and config file options of subcommand
+// after flags are parsed into c. Subcommands use only input options,
+// so the first profile is returned if config file has several profiles.
+func (c *Config) commandConfig(stderr io.Writer) (Config, error) {
+	if err := c.parseEnv(); err != nil {
+		return Config{}, fmt.Errorf("parse env: %w", err)
+	}
+	cfgs, err := c.configs()
+	if err != nil {
+		return Config{}, err
+	}
+	if len(cfgs) > 1 {
+		fmt.Fprintf(stderr, "WARNING: using %q profile, use -profile flag to select another one\n", cfgs[0].Profile)
+	}
+	return cfgs[0], nil
+}
+
+// configs applies config file options to c and returns
+// a config for each output profile with defaults set.
+func (c *Config) configs() ([]Config, error) {
+	cfgs, err := c.applyConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	for i := range cfgs {
+		cfgs[i].setDefaults()
+		cfgs[i].normalize()
+	}
+	return cfgs, nil
+}
+
+// applyConfigFile loads config file and applies its options and profiles to c.
+func (c *Config) applyConfigFile() ([]Config, error) {
+	path := c.ConfigFile
+	if path == "" {
+		dir := packageDir(c.Dir)
+		if dir == "" {
+			dir = "."
+		}
+		var err error
+		if path, err = findConfigFile(c.path(dir)); err != nil {
+			return nil, fmt.Errorf("find config file: %w", err)
+		}
+	}
+	if path == "" {
+		if c.Profile != "" {
+			return nil, fmt.Errorf("profile %q not found: no %s config file", c.Profile, ConfigFileName)
+		}
+		return []Config{*c}, nil
+	}
+	c.ConfigFile = path
+
+	file, err := loadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return configsFromFile(*c, file, c.Profile, c.flags)
+}
+
+// path resolves relative path against WorkDir.
+func (c Config) path(path string) string {
+	if c.WorkDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.WorkDir, path)
+}
+
+// resolvePaths resolves relative paths of output, template
+// and config files against WorkDir. Dir is resolved by the parser,
+// so positions of parsed files stay relative to WorkDir.
+func (c *Config) resolvePaths() {
+	if !isStdout(c.OutFile) {
+		c.OutFile = c.path(c.OutFile)
+	}
+	for i, o := range c.Outputs {
+		if !isStdout(o.File) {
+			c.Outputs[i].File = c.path(o.File)
+		}
+	}
+	if c.TemplateFile != "" {
+		c.TemplateFile = c.path(c.TemplateFile)
+	}
+	if c.ConfigFile != "" {
+		c.ConfigFile = c.path(c.ConfigFile)
+	}
+}
+
+// formatMarkerStyles maps output formats to marker styles of the host file,
+// it's used by edit mode if marker style can't be detected by output file name.
+var formatMarkerStyles = map[types.OutFormat]edit.MarkerStyle{
+	types.OutFormatMarkdown: edit.MarkerStyleHTML,
+	types.OutFormatHTML:     edit.MarkerStyleHTML,
+	types.OutFormatEnv:      edit.MarkerStyleHash,
+	types.OutFormatCompose:  edit.MarkerStyleHash,
+	types.OutFormatDocker:   edit.MarkerStyleHash,
+	types.OutFormatSystemd:  edit.MarkerStyleHash,
+	types.OutFormatShell:    edit.MarkerStyleHash,
+	types.OutFormatMan:      edit.MarkerStyleRoff,
+	types.OutFormatAsciiDoc: edit.MarkerStyleSlash,
+	types.OutFormatRST:      edit.MarkerStyleRST,
+	types.OutFormatGo:       edit.MarkerStyleSlash,
+}
+
+// editMarkerStyle returns marker style for edit mode: it's either
+// set by -marker-style flag, or detected by output file name or output format.
+func (c *Config) editMarkerStyle() (edit.MarkerStyle, error) {
+	if c.MarkerStyle != "" {
+		style, err := edit.ParseMarkerStyle(c.MarkerStyle)
+		if err != nil {
+			return edit.MarkerStyle{}, fmt.Errorf("parse marker style: %w", err)
+		}
+		return style, nil
+	}
+	if style, ok := edit.MarkerStyleForFile(c.OutFile); ok {
+		return style, nil
+	}
+	if style, ok := formatMarkerStyles[c.OutFormat]; ok {
+		return style, nil
+	}
+	return edit.MarkerStyle{}, fmt.Errorf("unknown marker style for %q file and %s format, use -marker-style flag",
+		c.OutFile, c.OutFormat)
 }
 
 func (c *Config) Validate() error {
+	if len(c.Outputs) > 0 {
+		if c.OutFile != "" {
+			return fmt.Errorf("output %q can't be combined with fmt=path outputs", c.OutFile)
+		}
+		for _, oc := range c.outputConfigs() {
+			if err := oc.Validate(); err != nil {
+				return fmt.Errorf("output %s: %w", oc.OutFile, err)
+			}
+		}
+		return nil
+	}
 	if c.Edit {
-		if c.OutFormat != "markdown" {
-			return fmt.Errorf("edit mode (-edit) only supports markdown format, got: %s", c.OutFormat)
+		if isStdout(c.OutFile) {
+			return errors.New("edit mode (-edit) requires -output file to be specified")
+		}
+		if _, err := c.editMarkerStyle(); err != nil {
+			return fmt.Errorf("edit mode (-edit): %w", err)
+		}
+	}
+	if c.AllTags && (c.BuildTags != "" || c.GOOS != "" || c.GOARCH != "") {
+		return errors.New("flag -all-tags can't be combined with -tags, -goos or -goarch")
+	}
+	if c.SourceLinkTemplate != "" && !strings.Contains(c.SourceLinkTemplate, "{file}") {
+		return fmt.Errorf("source link template %q must contain {file} placeholder", c.SourceLinkTemplate)
+	}
+	if c.Concurrency < 0 {
+		return fmt.Errorf("invalid concurrency %d: must not be negative", c.Concurrency)
+	}
+	if c.Watch {
+		if c.Check {
+			return errors.New("watch mode (-watch) can't be combined with check mode (-check)")
+		}
+		if isStdout(c.OutFile) {
+			return errors.New("watch mode (-watch) requires -output file to be specified")
+		}
+		if c.WatchInterval <= 0 {
+			return fmt.Errorf("invalid watch interval %s: must be positive", c.WatchInterval)
+		}
+	}
+	if c.Check && isStdout(c.OutFile) {
+		return errors.New("check mode (-check) requires -output file to be specified")
+	}
+	if c.Section != "" {
+		if !c.Edit {
+			return errors.New("flag -section requires edit mode (-edit)")
 		}
-		if c.OutFile == "" {
-			return errors.New("edit mode (-edit) requires -output flag to be specified")
+		if err := edit.ValidateSectionName(c.Section); err != nil {
+			return fmt.Errorf("invalid -section flag: %w", err)
 		}
 	}
 
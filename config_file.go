@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/g4s8/envdoc/types"
+)
+
+// ConfigFileName is a name of config file, it's searched
+// in the -dir directory and its parents.
+const ConfigFileName = ".envdoc.yaml"
+
+// configFile is a content of .envdoc.yaml file: default options
+// and named profiles. Each profile generates one output.
+type configFile struct {
+	configOptions `yaml:",inline"`
+	Profiles      []configOptions `yaml:"profiles"`
+}
+
+// configOptions are options of config file and its profiles,
+// keys are the same as flag names. Empty values are not applied,
+// bool options are pointers to apply explicit false values too.
+type configOptions struct {
+	Name               string `yaml:"name"`
+	Dir                string `yaml:"dir"`
+	Files              string `yaml:"files"`
+	Types              string `yaml:"types"`
+	Concurrency        int    `yaml:"concurrency"`
+	Exclude            string `yaml:"exclude"`
+	SkipNestedModules  *bool  `yaml:"skip-nested-modules"`
+	Tags               string `yaml:"tags"`
+	GOOS               string `yaml:"goos"`
+	GOARCH             string `yaml:"goarch"`
+	AllTags            *bool  `yaml:"all-tags"`
+	Target             string `yaml:"target"`
+	Output             string `yaml:"output"`
+	Format             string `yaml:"format"`
+	NoStyles           *bool  `yaml:"no-styles"`
+	ComposeService     string `yaml:"compose-service"`
+	ManSectionOnly     *bool  `yaml:"man-section-only"`
+	GoPackage          string `yaml:"go-package"`
+	Template           string `yaml:"template"`
+	SourceLinkTemplate string `yaml:"source-link-template"`
+	Edit               *bool  `yaml:"edit"`
+	MarkerStyle        string `yaml:"marker-style"`
+	Section            string `yaml:"section"`
+	EnvPrefix          string `yaml:"env-prefix"`
+	FieldNames         *bool  `yaml:"field-names"`
+	TagName            string `yaml:"tag-name"`
+	TagDefault         string `yaml:"tag-default"`
+	RequiredIfNoDef    *bool  `yaml:"required-if-no-def"`
+}
+
+// findConfigFile searches config file in dir and its parents,
+// it returns empty path if config file is not found.
+func findConfigFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("get absolute path: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("stat config file: %w", err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadConfigFile reads and parses config file,
+// unknown keys and invalid values are reported with line numbers.
+func loadConfigFile(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	var res configFile
+	if err := yaml.UnmarshalStrict(data, &res); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	if res.Name != "" {
+		return nil, fmt.Errorf("config file %s: name is allowed only in profiles", path)
+	}
+	names := make(map[string]struct{}, len(res.Profiles))
+	for i, p := range res.Profiles {
+		if p.Name == "" {
+			continue
+		}
+		if _, ok := names[p.Name]; ok {
+			return nil, fmt.Errorf("config file %s: profiles[%d]: duplicate profile name %q", path, i, p.Name)
+		}
+		names[p.Name] = struct{}{}
+	}
+	res.resolvePaths(filepath.Dir(path))
+	return &res, nil
+}
+
+// resolvePaths resolves relative output and template paths of options
+// and profiles against config file dir.
+func (f *configFile) resolvePaths(dir string) {
+	f.configOptions.resolvePaths(dir)
+	for i := range f.Profiles {
+		f.Profiles[i].resolvePaths(dir)
+	}
+}
+
+func (o *configOptions) resolvePaths(dir string) {
+	o.Output = configFilePath(dir, o.Output)
+	o.Template = configFilePath(dir, o.Template)
+}
+
+// configFilePath resolves relative path of config file option against
+// config file dir, the result is relative to the current directory if possible.
+func configFilePath(dir, path string) string {
+	if isStdout(path) || filepath.IsAbs(path) {
+		return path
+	}
+	path = filepath.Join(dir, path)
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			return rel
+		}
+	}
+	return path
+}
+
+// apply sets options to config, flags which are set explicitly are not overridden.
+//
+//nolint:cyclop,gocognit
+func (o *configOptions) apply(c *Config, flags map[string]bool) error {
+	setString := func(flag string, dst *string, val string) {
+		if val != "" && !flags[flag] {
+			*dst = val
+		}
+	}
+	setBool := func(flag string, dst *bool, val *bool) {
+		if val != nil && !flags[flag] {
+			*dst = *val
+		}
+	}
+	setString("dir", &c.Dir, o.Dir)
+	setString("files", &c.FileGlob, o.Files)
+	setString("types", &c.TypeGlob, o.Types)
+	if o.Concurrency != 0 && !flags["concurrency"] {
+		c.Concurrency = o.Concurrency
+	}
+	setString("exclude", &c.Exclude, o.Exclude)
+	setBool("skip-nested-modules", &c.SkipNestedModules, o.SkipNestedModules)
+	setString("tags", &c.BuildTags, o.Tags)
+	setString("goos", &c.GOOS, o.GOOS)
+	setString("goarch", &c.GOARCH, o.GOARCH)
+	setBool("all-tags", &c.AllTags, o.AllTags)
+	if o.Target != "" && !flags["target"] {
+		target, err := types.ParseTargetType(o.Target)
+		if err != nil {
+			return fmt.Errorf("target: %w", err)
+		}
+		c.Target = target
+	}
+	setString("output", &c.OutFile, o.Output)
+	setString("format", (*string)(&c.OutFormat), o.Format)
+	setBool("no-styles", &c.NoStyles, o.NoStyles)
+	setString("compose-service", &c.ComposeService, o.ComposeService)
+	setBool("man-section-only", &c.ManSectionOnly, o.ManSectionOnly)
+	setString("go-package", &c.GoPackage, o.GoPackage)
+	setString("template", &c.TemplateFile, o.Template)
+	setString("source-link-template", &c.SourceLinkTemplate, o.SourceLinkTemplate)
+	setBool("edit", &c.Edit, o.Edit)
+	setString("marker-style", &c.MarkerStyle, o.MarkerStyle)
+	setString("section", &c.Section, o.Section)
+	setString("env-prefix", &c.EnvPrefix, o.EnvPrefix)
+	setBool("field-names", &c.FieldNames, o.FieldNames)
+	setString("tag-name", &c.TagName, o.TagName)
+	setString("tag-default", &c.TagDefault, o.TagDefault)
+	setBool("required-if-no-def", &c.RequiredIfNoDef, o.RequiredIfNoDef)
+	return nil
+}
+
+// configsFromFile applies default options of config file to c and returns
+// configs of profiles. If profile name is not empty, only this profile is returned.
+// If config file has no profiles or -output flag is set without profile name,
+// c is the only config.
+func configsFromFile(c Config, file *configFile, profile string, flags map[string]bool) ([]Config, error) {
+	if err := file.apply(&c, flags); err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	if len(file.Profiles) == 0 {
+		if profile != "" {
+			return nil, fmt.Errorf("profile %q not found: config file has no profiles", profile)
+		}
+		return []Config{c}, nil
+	}
+	// explicit -output flag generates single output without profiles
+	if profile == "" && flags["output"] {
+		return []Config{c}, nil
+	}
+
+	var res []Config
+	for i, p := range file.Profiles {
+		if profile != "" && p.Name != profile {
+			continue
+		}
+		pc := c
+		if err := p.apply(&pc, flags); err != nil {
+			return nil, fmt.Errorf("config file: profiles[%d]: %w", i, err)
+		}
+		pc.Profile = p.Name
+		res = append(res, pc)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("profile %q not found", profile)
+	}
+	return res, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func writeConfigFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+func TestFindConfigFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	path, err := findConfigFile(nested)
+	testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+	testutils.AssertError(t, path == "", "unexpected config file: %q", path)
+
+	expect := writeConfigFile(t, root, "")
+	path, err = findConfigFile(nested)
+	testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+	testutils.AssertError(t, path == expect, "unexpected config file: %q", path)
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		path := writeConfigFile(t, t.TempDir(), `
+env-prefix: APP_
+profiles:
+  - name: docs
+    types: Config
+    format: markdown
+    output: ENV.md
+  - format: dotenv
+    output: .env.example
+`)
+		file, err := loadConfigFile(path)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, file.EnvPrefix == "APP_", "unexpected EnvPrefix: %q", file.EnvPrefix)
+		testutils.AssertFatal(t, len(file.Profiles) == 2, "unexpected profiles: %d", len(file.Profiles))
+		testutils.AssertError(t, file.Profiles[0].Name == "docs", "unexpected name: %q", file.Profiles[0].Name)
+		// paths are relative to config file dir
+		out, err := filepath.Abs(file.Profiles[1].Output)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, out == filepath.Join(filepath.Dir(path), ".env.example"),
+			"unexpected output: %q", file.Profiles[1].Output)
+	})
+	t.Run("relative paths", func(t *testing.T) {
+		root := t.TempDir()
+		nested := filepath.Join(root, "cmd", "app")
+		if err := os.MkdirAll(nested, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeConfigFile(t, root, `
+output: docs/ENV.md
+template: templates/env.tmpl
+profiles:
+  - output: "-"
+  - output: /tmp/env.md
+`)
+		t.Chdir(nested)
+		path, err := findConfigFile(".")
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		file, err := loadConfigFile(path)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		expect := filepath.Join("..", "..", "docs", "ENV.md")
+		testutils.AssertError(t, file.Output == expect, "unexpected output: %q", file.Output)
+		expect = filepath.Join("..", "..", "templates", "env.tmpl")
+		testutils.AssertError(t, file.Template == expect, "unexpected template: %q", file.Template)
+		testutils.AssertError(t, file.Profiles[0].Output == "-", "unexpected stdout output: %q", file.Profiles[0].Output)
+		testutils.AssertError(t, file.Profiles[1].Output == "/tmp/env.md", "unexpected absolute output: %q", file.Profiles[1].Output)
+	})
+	for _, tc := range []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "unknown key",
+			content: "profiles:\n  - format: dotenv\n    outptu: .env\n",
+			err:     "line 3: field outptu not found",
+		},
+		{
+			name:    "invalid value",
+			content: "no-styles: maybe\n",
+			err:     "line 1: cannot unmarshal",
+		},
+		{
+			name:    "duplicate profile",
+			content: "profiles:\n  - name: docs\n  - name: docs\n",
+			err:     `profiles[1]: duplicate profile name "docs"`,
+		},
+		{
+			name:    "name outside profile",
+			content: "name: docs\n",
+			err:     "name is allowed only in profiles",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeConfigFile(t, t.TempDir(), tc.content)
+			_, err := loadConfigFile(path)
+			testutils.AssertFatal(t, err != nil, "expected error")
+			testutils.AssertError(t, strings.Contains(err.Error(), tc.err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestConfigsFromFile(t *testing.T) {
+	file := &configFile{
+		configOptions: configOptions{
+			EnvPrefix: "APP_",
+			Format:    "html",
+		},
+		Profiles: []configOptions{
+			{Name: "docs", Types: "Config", Format: "markdown", Output: "ENV.md"},
+			{Name: "env", Format: "dotenv", Output: ".env.example"},
+		},
+	}
+	base := Config{OutFormat: types.OutFormatMarkdown, Target: types.TargetTypeCaarlos0}
+
+	t.Run("all profiles", func(t *testing.T) {
+		cfgs, err := configsFromFile(base, file, "", map[string]bool{})
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertFatal(t, len(cfgs) == 2, "unexpected configs: %d", len(cfgs))
+		testutils.AssertError(t, cfgs[0].OutFile == "ENV.md", "unexpected OutFile: %q", cfgs[0].OutFile)
+		testutils.AssertError(t, cfgs[0].TypeGlob == "Config", "unexpected TypeGlob: %q", cfgs[0].TypeGlob)
+		testutils.AssertError(t, cfgs[0].EnvPrefix == "APP_", "unexpected EnvPrefix: %q", cfgs[0].EnvPrefix)
+		testutils.AssertError(t, cfgs[1].OutFormat == types.OutFormatEnv, "unexpected OutFormat: %q", cfgs[1].OutFormat)
+		testutils.AssertError(t, cfgs[1].TypeGlob == "", "unexpected TypeGlob: %q", cfgs[1].TypeGlob)
+		testutils.AssertError(t, cfgs[1].Profile == "env", "unexpected Profile: %q", cfgs[1].Profile)
+	})
+	t.Run("flags override", func(t *testing.T) {
+		c := base
+		c.EnvPrefix = "FLAG_"
+		c.OutFormat = types.OutFormatTxt
+		cfgs, err := configsFromFile(c, file, "docs", map[string]bool{"env-prefix": true, "format": true})
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertFatal(t, len(cfgs) == 1, "unexpected configs: %d", len(cfgs))
+		testutils.AssertError(t, cfgs[0].EnvPrefix == "FLAG_", "unexpected EnvPrefix: %q", cfgs[0].EnvPrefix)
+		testutils.AssertError(t, cfgs[0].OutFormat == types.OutFormatTxt, "unexpected OutFormat: %q", cfgs[0].OutFormat)
+		testutils.AssertError(t, cfgs[0].OutFile == "ENV.md", "unexpected OutFile: %q", cfgs[0].OutFile)
+	})
+	t.Run("output flag", func(t *testing.T) {
+		c := base
+		c.OutFile = "doc.html"
+		cfgs, err := configsFromFile(c, file, "", map[string]bool{"output": true})
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertFatal(t, len(cfgs) == 1, "unexpected configs: %d", len(cfgs))
+		testutils.AssertError(t, cfgs[0].OutFile == "doc.html", "unexpected OutFile: %q", cfgs[0].OutFile)
+		testutils.AssertError(t, cfgs[0].OutFormat == types.OutFormatHTML, "unexpected OutFormat: %q", cfgs[0].OutFormat)
+	})
+	t.Run("bool override", func(t *testing.T) {
+		path := writeConfigFile(t, t.TempDir(), `
+edit: true
+profiles:
+  - name: plain
+    edit: false
+  - name: edit
+`)
+		file, err := loadConfigFile(path)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		cfgs, err := configsFromFile(base, file, "", map[string]bool{})
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertFatal(t, len(cfgs) == 2, "unexpected configs: %d", len(cfgs))
+		testutils.AssertError(t, !cfgs[0].Edit, "expected edit disabled by profile")
+		testutils.AssertError(t, cfgs[1].Edit, "expected edit from defaults")
+	})
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := configsFromFile(base, file, "nope", map[string]bool{})
+		testutils.AssertError(t, err != nil, "expected error for unknown profile")
+	})
+	t.Run("invalid target", func(t *testing.T) {
+		bad := &configFile{Profiles: []configOptions{{Target: "nope"}}}
+		_, err := configsFromFile(base, bad, "", map[string]bool{})
+		testutils.AssertFatal(t, err != nil, "expected error for invalid target")
+		testutils.AssertError(t, strings.Contains(err.Error(), "profiles[0]: target"), "unexpected error: %v", err)
+	})
+}
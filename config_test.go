@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"flag"
-	"os"
+	"strings"
 	"testing"
 
+	"github.com/g4s8/envdoc/edit"
 	"github.com/g4s8/envdoc/testutils"
 )
 
@@ -12,21 +14,30 @@ func TestConfig(t *testing.T) {
 	t.Run("parse flags", func(t *testing.T) {
 		var c Config
 		fs := flag.NewFlagSet("test", flag.ContinueOnError)
-		os.Args = []string{
-			"test",
+		args := []string{
 			"-types", "foo,bar",
 			"-files", "*",
 			"-output", "out.txt",
 			"-format", "plaintext",
 			"-env-prefix", "FOO",
 			"-no-styles",
+			"-compose-service", "web",
+			"-go-package", "envs",
+			"-template", "custom.tmpl",
+			"-section", "server",
+			"-check",
 			"-field-names",
 			"-debug",
 			"-tag-name", "xenv",
 			"-tag-default", "default",
 			"-required-if-no-def",
+			"-concurrency", "4",
+			"-exclude", "gen, legacy/*",
+			"-skip-nested-modules",
+			"-tags", "debug,trace",
+			"-goos", "windows",
 		}
-		if err := c.parseFlags(fs); err != nil {
+		if err := c.parseFlags(fs, args); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		testutils.AssertError(t, c.TypeGlob == "foo,bar", "unexpected TypeGlob: %q", c.TypeGlob)
@@ -35,16 +46,29 @@ func TestConfig(t *testing.T) {
 		testutils.AssertError(t, c.OutFormat == "plaintext", "unexpected OutFormat: %q", c.OutFormat)
 		testutils.AssertError(t, c.EnvPrefix == "FOO", "unexpected EnvPrefix: %q", c.EnvPrefix)
 		testutils.AssertError(t, c.NoStyles, "unexpected NoStyles: false")
+		testutils.AssertError(t, c.ComposeService == "web", "unexpected ComposeService: %q", c.ComposeService)
+		testutils.AssertError(t, c.GoPackage == "envs", "unexpected GoPackage: %q", c.GoPackage)
+		testutils.AssertError(t, c.TemplateFile == "custom.tmpl", "unexpected TemplateFile: %q", c.TemplateFile)
+		testutils.AssertError(t, c.Section == "server", "unexpected Section: %q", c.Section)
+		testutils.AssertError(t, c.Check, "unexpected Check: false")
 		testutils.AssertError(t, c.FieldNames, "unexpected FieldNames: false")
 		testutils.AssertError(t, c.Debug, "unexpected Debug: false")
 		testutils.AssertError(t, c.TagName == "xenv", "unexpected TagName: %q", c.TagName)
 		testutils.AssertError(t, c.TagDefault == "default", "unexpected TagDefault: %q", c.TagDefault)
 		testutils.AssertError(t, c.RequiredIfNoDef, "unexpected RequiredIfNoDef: false")
+		testutils.AssertError(t, c.Concurrency == 4, "unexpected Concurrency: %d", c.Concurrency)
+		testutils.AssertError(t, strings.Join(c.excludes(), "|") == "gen|legacy/*", "unexpected excludes: %q", c.excludes())
+		testutils.AssertError(t, c.SkipNestedModules, "unexpected SkipNestedModules: false")
+		ctx := c.buildContext()
+		testutils.AssertFatal(t, ctx != nil, "unexpected nil build context")
+		testutils.AssertError(t, ctx.GOOS == "windows", "unexpected GOOS: %q", ctx.GOOS)
+		testutils.AssertError(t, strings.Join(ctx.BuildTags, ",") == "debug,trace", "unexpected BuildTags: %v", ctx.BuildTags)
 	})
 	t.Run("normalize", func(t *testing.T) {
 		var c Config
 		c.TypeGlob = `"foo"`
 		c.FileGlob = `"*"`
+		c.Dir = "./internal/..."
 		c.normalize()
 		if c.TypeGlob != "foo" {
 			t.Errorf("unexpected TypeGlob: %q", c.TypeGlob)
@@ -52,6 +76,9 @@ func TestConfig(t *testing.T) {
 		if c.FileGlob != "*" {
 			t.Errorf("unexpected FileGlob: %q", c.FileGlob)
 		}
+		if c.Dir != "./internal" {
+			t.Errorf("unexpected Dir: %q", c.Dir)
+		}
 	})
 	t.Run("validate", func(t *testing.T) {
 		var c Config
@@ -59,5 +86,95 @@ func TestConfig(t *testing.T) {
 		c.OutFormat = "text"
 		err := c.Validate()
 		testutils.AssertError(t, err != nil, "expected error for invalid config")
+
+		c.OutFormat = "man"
+		c.OutFile = "app.1"
+		err = c.Validate()
+		testutils.AssertError(t, err == nil, "unexpected error: %v", err)
+
+		c.OutFormat = "plaintext"
+		c.OutFile = "notes.txt"
+		err = c.Validate()
+		testutils.AssertError(t, err != nil, "expected error for unknown marker style")
+
+		c.MarkerStyle = "hash"
+		err = c.Validate()
+		testutils.AssertError(t, err == nil, "unexpected error: %v", err)
+
+		c.MarkerStyle = ""
+		c.OutFormat = "dotenv"
+		c.OutFile = ".env.example"
+		style, err := c.editMarkerStyle()
+		testutils.AssertError(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, style == edit.MarkerStyleHash, "unexpected marker style: %+v", style)
+
+		c.Section = "server"
+		err = c.Validate()
+		testutils.AssertError(t, err == nil, "unexpected error: %v", err)
+
+		c.Section = "server config"
+		err = c.Validate()
+		testutils.AssertError(t, err != nil, "expected error for invalid section name")
+
+		c.Section = "server"
+		c.Edit = false
+		err = c.Validate()
+		testutils.AssertError(t, err != nil, "expected error for section without edit mode")
+
+		c = Config{AllTags: true}
+		err = c.Validate()
+		testutils.AssertError(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, c.buildContext() == nil, "unexpected build context in all tags mode")
+
+		c.BuildTags = "debug"
+		err = c.Validate()
+		testutils.AssertError(t, err != nil, "expected error for -all-tags with -tags")
+
+		c = Config{Watch: true, WatchInterval: DefaultWatchInterval, OutFile: "ENV.md"}
+		err = c.Validate()
+		testutils.AssertError(t, err == nil, "unexpected error: %v", err)
+
+		c.Check = true
+		err = c.Validate()
+		testutils.AssertError(t, err != nil, "expected error for -watch with -check")
+
+		c.Check = false
+		c.OutFile = "-"
+		err = c.Validate()
+		testutils.AssertError(t, err != nil, "expected error for -watch without output file")
+	})
+	t.Run("standalone", func(t *testing.T) {
+		t.Setenv("GOFILE", "")
+		t.Setenv("GOLINE", "")
+		var c Config
+		err := c.parseEnv()
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, c.ExecFile == "", "unexpected ExecFile: %q", c.ExecFile)
+
+		c.Edit = true
+		c.OutFile = "-"
+		testutils.AssertError(t, c.Validate() != nil, "expected error for edit mode with stdout")
+	})
+	t.Run("go generate", func(t *testing.T) {
+		t.Setenv("GOFILE", "config.go")
+		t.Setenv("GOLINE", "")
+		var c Config
+		err := c.parseEnv()
+		testutils.AssertError(t, errors.Is(err, ErrNotCalledByGoGenerate), "unexpected error: %v", err)
+
+		t.Setenv("GOLINE", "7")
+		err = c.parseEnv()
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, c.ExecFile == "config.go", "unexpected ExecFile: %q", c.ExecFile)
+		testutils.AssertError(t, c.ExecLine == 7, "unexpected ExecLine: %d", c.ExecLine)
+	})
+	t.Run("print", func(t *testing.T) {
+		c := Config{ExecFile: "config.go", ExecLine: 7}
+		var out strings.Builder
+		c.fprint(&out)
+		for _, expect := range []string{`ExecFile: "config.go"`, "ExecLine: 7"} {
+			testutils.AssertError(t, strings.Contains(out.String(), expect),
+				"expected %q in output: %q", expect, out.String())
+		}
 	})
 }
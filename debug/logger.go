@@ -13,6 +13,11 @@ func (l *nopLogger) Logf(_ string, _ ...interface{}) {}
 
 func (l *nopLogger) Log(_ ...interface{}) {}
 
+// NopLogger returns a logger which discards all messages.
+func NopLogger() Logger {
+	return &nopLogger{}
+}
+
 var logger Logger
 
 type Printer interface {
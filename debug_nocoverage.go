@@ -13,7 +13,7 @@ func printScopesTree(s []*types.EnvScope) {
 	}
 	debug.Log("Scopes tree:\n")
 	for _, scope := range s {
-		debug.Logf(" - %q\n", scope.Name)
+		debug.Logf(" - %q at %s:%d\n", scope.Name, scope.File, scope.Line)
 		for _, item := range scope.Vars {
 			printDocItem("  ", item)
 		}
@@ -21,7 +21,7 @@ func printScopesTree(s []*types.EnvScope) {
 }
 
 func printDocItem(prefix string, item *types.EnvDocItem) {
-	debug.Logf("%s- %q\n", prefix, item.Name)
+	debug.Logf("%s- %q at %s:%d\n", prefix, item.Name, item.File, item.Line)
 	for _, child := range item.Children {
 		printDocItem(prefix+"  ", child)
 	}
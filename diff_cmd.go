@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/g4s8/envdoc/edit"
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/types"
+)
+
+// Kinds of spec changes.
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeRenamed = "renamed"
+	changeChanged = "changed"
+)
+
+// specChange is a change of documented variable between two versions of spec.
+type specChange struct {
+	// Kind of the change: added, removed, renamed or changed.
+	Kind string `json:"kind"`
+	// Name of the variable, it's a new name for renamed variable.
+	Name string `json:"name"`
+	// OldName is a previous name of renamed variable.
+	OldName string `json:"old_name,omitempty"`
+	// Doc is a documentation text of the variable.
+	Doc string `json:"doc,omitempty"`
+	// Details describe changes of variable options.
+	Details []string `json:"details,omitempty"`
+	// Breaking is true if deployments may need changes.
+	Breaking bool `json:"-"`
+}
+
+// diffCommand compares two versions of documented variables: JSON docs
+// of old and new versions, or sources of base checkout and working tree.
+// It writes a changelog with breaking changes listed separately.
+func diffCommand(args []string, stdout, stderr io.Writer) int {
+	var c Config
+	f := flag.NewFlagSet("envdoc diff", flag.ContinueOnError)
+	f.SetOutput(stderr)
+	var baseRef, renameHints string
+	f.StringVar(&baseRef, "base-ref", "", "Older checkout directory or git ref to compare sources with")
+	f.StringVar(&renameHints, "rename", "", "Comma-separated OLD=NEW names of renamed variables")
+	if err := c.parseFlags(f, args); err != nil {
+		return 2
+	}
+	renames, err := parseRenames(renameHints)
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid -rename flag: %v\n", err)
+		return 2
+	}
+	if c.OutFormat != types.OutFormatMarkdown && c.OutFormat != types.OutFormatJSON {
+		fmt.Fprintf(stderr, "Unsupported format %q, use markdown or json\n", c.OutFormat)
+		return 2
+	}
+
+	var oldSpec, newSpec []*types.EnvScope
+	switch {
+	case baseRef != "" && f.NArg() == 0:
+		oldSpec, newSpec, err = refSpecs(&c, baseRef, stderr)
+	case baseRef == "" && f.NArg() == 2:
+		if oldSpec, err = readSpecFile(f.Arg(0)); err == nil {
+			newSpec, err = readSpecFile(f.Arg(1))
+		}
+	default:
+		fmt.Fprintln(stderr, "Usage: envdoc diff [flags] old.json new.json, or envdoc diff -base-ref REF [flags]")
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load spec: %v\n", err)
+		return 2
+	}
+
+	changes := diffSpecs(oldSpec, newSpec, renames)
+	var buf bytes.Buffer
+	if c.OutFormat == types.OutFormatJSON {
+		err = writeChangelogJSON(&buf, changes)
+	} else {
+		writeChangelogMarkdown(&buf, changes)
+	}
+	if err == nil {
+		if isStdout(c.OutFile) {
+			_, err = stdout.Write(buf.Bytes())
+		} else {
+			err = edit.WriteFileAtomic(c.OutFile, buf.Bytes(), 0o644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to write changelog: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseRenames parses comma-separated OLD=NEW rename hints.
+func parseRenames(s string) (map[string]string, error) {
+	res := make(map[string]string)
+	if s == "" {
+		return res, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		oldName, newName, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || oldName == "" || newName == "" {
+			return nil, fmt.Errorf("expected OLD=NEW, got %q", pair)
+		}
+		res[oldName] = newName
+	}
+	return res, nil
+}
+
+func readSpecFile(path string) ([]*types.EnvScope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open spec: %w", err)
+	}
+	defer f.Close()
+	scopes, err := render.ReadJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return scopes, nil
+}
+
+// refSpecs parses sources of config dir in the working tree and in the base
+// version: an older checkout directory of the repository, or a temporary
+// checkout of git ref.
+func refSpecs(c *Config, base string, stderr io.Writer) (oldSpec, newSpec []*types.EnvScope, err error) {
+	cfg, newSpec, err := commandScopes(c, stderr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := repoRoot(cfg.Dir)
+	abs, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get dir path: %w", err)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get dir path: %w", err)
+	}
+	checkout := base
+	if info, statErr := os.Stat(base); statErr != nil || !info.IsDir() {
+		dir, cleanup, err := checkoutRef(root, base)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer cleanup()
+		checkout = dir
+	}
+	oldCfg := cfg
+	oldCfg.Dir = filepath.Join(checkout, rel)
+	if oldSpec, err = loadScopes(oldCfg, cacheOptions(oldCfg)...); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", base, err)
+	}
+	return oldSpec, newSpec, nil
+}
+
+// checkoutRef checks out git ref of repository root into a temporary
+// worktree, cleanup removes it.
+func checkoutRef(root, ref string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "envdoc-base-")
+	if err != nil {
+		return "", nil, fmt.Errorf("create checkout dir: %w", err)
+	}
+	if _, err := gitOutput(root, "worktree", "add", "--detach", dir, ref); err != nil {
+		os.RemoveAll(dir) //nolint:errcheck
+		return "", nil, err
+	}
+	cleanup := func() {
+		gitOutput(root, "worktree", "remove", "--force", dir) //nolint:errcheck
+		os.RemoveAll(dir)                                     //nolint:errcheck
+	}
+	return dir, cleanup, nil
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// flattenSpec returns named variables of scopes in declaration order,
+// nested variables are included, duplicates are skipped.
+func flattenSpec(scopes []*types.EnvScope) []*types.EnvDocItem {
+	var res []*types.EnvDocItem
+	seen := make(map[string]bool)
+	var walk func(items []*types.EnvDocItem)
+	walk = func(items []*types.EnvDocItem) {
+		for _, item := range items {
+			if item.Name != "" && !seen[item.Name] {
+				seen[item.Name] = true
+				res = append(res, item)
+			}
+			walk(item.Children)
+		}
+	}
+	for _, scope := range scopes {
+		walk(scope.Vars)
+	}
+	return res
+}
+
+// diffSpecs compares variables of two spec versions by full name.
+// Removed and added variables are reported as renamed if renames has
+// the hint of old name, or if they have the same type and documentation.
+func diffSpecs(oldSpec, newSpec []*types.EnvScope, renames map[string]string) []specChange {
+	oldVars, newVars := flattenSpec(oldSpec), flattenSpec(newSpec)
+	oldByName := make(map[string]*types.EnvDocItem, len(oldVars))
+	for _, v := range oldVars {
+		oldByName[v.Name] = v
+	}
+	newByName := make(map[string]*types.EnvDocItem, len(newVars))
+	for _, v := range newVars {
+		newByName[v.Name] = v
+	}
+
+	// match renames of removed variables: explicit hints first,
+	// then variables with the same documentation
+	renamedFrom := make(map[string]*types.EnvDocItem)
+	renamed := make(map[string]bool)
+	for _, o := range oldVars {
+		name, ok := renames[o.Name]
+		if !ok || newByName[o.Name] != nil || oldByName[name] != nil || renamedFrom[name] != nil {
+			continue
+		}
+		if n := newByName[name]; n != nil {
+			renamedFrom[name] = o
+			renamed[o.Name] = true
+		}
+	}
+	for _, o := range oldVars {
+		if newByName[o.Name] != nil || renamed[o.Name] {
+			continue
+		}
+		var best *types.EnvDocItem
+		var bestScore int
+		for _, n := range newVars {
+			if oldByName[n.Name] != nil || renamedFrom[n.Name] != nil {
+				continue
+			}
+			if score := renameScore(o, n); score > bestScore {
+				best, bestScore = n, score
+			}
+		}
+		if best != nil {
+			renamedFrom[best.Name] = o
+			renamed[o.Name] = true
+		}
+	}
+
+	var res []specChange
+	for _, n := range newVars {
+		o := oldByName[n.Name]
+		switch {
+		case o != nil:
+			if details, breaking := varChanges(o, n); len(details) > 0 {
+				res = append(res, specChange{Kind: changeChanged, Name: n.Name, Doc: n.Doc, Details: details, Breaking: breaking})
+			}
+		case renamedFrom[n.Name] != nil:
+			o = renamedFrom[n.Name]
+			details, _ := varChanges(o, n)
+			res = append(res, specChange{
+				Kind: changeRenamed, Name: n.Name, OldName: o.Name, Doc: n.Doc,
+				Details: details, Breaking: true,
+			})
+		default:
+			c := specChange{Kind: changeAdded, Name: n.Name, Doc: n.Doc}
+			if n.Opts.Required && n.Opts.Default == "" {
+				c.Details = []string{"required without default value"}
+				c.Breaking = true
+			}
+			res = append(res, c)
+		}
+	}
+	for _, o := range oldVars {
+		if newByName[o.Name] == nil && !renamed[o.Name] {
+			res = append(res, specChange{Kind: changeRemoved, Name: o.Name, Doc: o.Doc, Breaking: true})
+		}
+	}
+	return res
+}
+
+// renameScore estimates if n is a renamed o variable, it's zero
+// if variables have different types or documentation. Options and
+// name are used to choose the best match of the same documentation.
+func renameScore(o, n *types.EnvDocItem) int {
+	if o.Type != n.Type || o.Doc == "" || o.Doc != n.Doc {
+		return 0
+	}
+	score := 1
+	if o.Opts == n.Opts {
+		score++
+	}
+	if lastNamePart(o.Name) == lastNamePart(n.Name) {
+		score++
+	}
+	return score
+}
+
+func lastNamePart(name string) string {
+	return name[strings.LastIndex(name, "_")+1:]
+}
+
+// varChanges describes changes of variable options, breaking is true
+// if existing deployments may fail or change behavior.
+func varChanges(o, n *types.EnvDocItem) (details []string, breaking bool) {
+	add := func(brk bool, format string, args ...any) {
+		details = append(details, fmt.Sprintf(format, args...))
+		breaking = breaking || brk
+	}
+	oo, no := o.Opts, n.Opts
+	if o.Type != n.Type && o.Type != "" && n.Type != "" {
+		add(true, "type changed from `%s` to `%s`", o.Type, n.Type)
+	}
+	switch {
+	case !oo.Required && no.Required:
+		add(no.Default == "", "became required")
+	case oo.Required && !no.Required:
+		add(false, "is no longer required")
+	}
+	switch {
+	case oo.Default != "" && no.Default == "":
+		add(true, "lost default value `%s`", oo.Default)
+	case oo.Default == "" && no.Default != "":
+		add(false, "got default value `%s`", no.Default)
+	case oo.Default != no.Default:
+		add(false, "default changed from `%s` to `%s`", oo.Default, no.Default)
+	}
+	if !oo.NonEmpty && no.NonEmpty {
+		add(true, "became non-empty")
+	} else if oo.NonEmpty && !no.NonEmpty {
+		add(false, "can be empty")
+	}
+	if oo.FromFile != no.FromFile {
+		if no.FromFile {
+			add(true, "value became a file path")
+		} else {
+			add(true, "value is no longer a file path")
+		}
+	}
+	if oo.Separator != no.Separator && strings.HasPrefix(n.Type, "[]") {
+		add(true, "separator changed from `%s` to `%s`", separatorName(oo.Separator), separatorName(no.Separator))
+	}
+	return details, breaking
+}
+
+func separatorName(sep string) string {
+	if sep == "" {
+		return ","
+	}
+	return sep
+}
+
+func (c specChange) describe() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "`%s`", c.Name)
+	switch c.Kind {
+	case changeRenamed:
+		fmt.Fprintf(&sb, " renamed from `%s`", c.OldName)
+	case changeAdded, changeRemoved:
+		sb.WriteString(" " + c.Kind)
+	}
+	if len(c.Details) > 0 {
+		if c.Kind == changeChanged {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strings.Join(c.Details, ", "))
+	}
+	if c.Doc != "" && c.Kind == changeAdded {
+		sb.WriteString(" - " + strings.ReplaceAll(c.Doc, "\n", " "))
+	}
+	return sb.String()
+}
+
+func writeChangelogMarkdown(w io.Writer, changes []specChange) {
+	fmt.Fprintln(w, "# Environment variables changes")
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "\nNo changes.")
+		return
+	}
+	sections := []struct {
+		title string
+		match func(specChange) bool
+	}{
+		{"Breaking changes", func(c specChange) bool { return c.Breaking }},
+		{"Added", func(c specChange) bool { return !c.Breaking && c.Kind == changeAdded }},
+		{"Changed", func(c specChange) bool { return !c.Breaking && c.Kind == changeChanged }},
+	}
+	for _, s := range sections {
+		var lines []string
+		for _, c := range changes {
+			if s.match(c) {
+				lines = append(lines, " - "+c.describe())
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(w, "\n## %s\n\n%s\n", s.title, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+func writeChangelogJSON(w io.Writer, changes []specChange) error {
+	log := struct {
+		Breaking []specChange `json:"breaking"`
+		Changes  []specChange `json:"changes"`
+	}{Breaking: []specChange{}, Changes: []specChange{}}
+	for _, c := range changes {
+		if c.Breaking {
+			log.Breaking = append(log.Breaking, c)
+		} else {
+			log.Changes = append(log.Changes, c)
+		}
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal changelog: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func TestDiffSpecs(t *testing.T) {
+	oldSpec := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{Name: "PORT", Doc: "Port to listen on.", Type: "int"},
+				{Name: "DEBUG", Type: "bool", Opts: types.EnvVarOptions{Default: "false"}},
+				{Name: "TIMEOUT", Type: "time.Duration", Opts: types.EnvVarOptions{Default: "5s"}},
+				{Name: "PREFIX", Type: "string"},
+				{Name: "LEGACY", Type: "string"},
+				{
+					Doc: "Database config.",
+					Children: []*types.EnvDocItem{
+						{Name: "DB_HOST", Type: "string", Opts: types.EnvVarOptions{Required: true}},
+					},
+				},
+			},
+		},
+	}
+	newSpec := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{Name: "LISTEN_PORT", Doc: "Port to listen on.", Type: "int"},
+				{Name: "DEBUG", Type: "bool", Opts: types.EnvVarOptions{Default: "true"}},
+				{Name: "TIMEOUT", Type: "time.Duration"},
+				{Name: "PREFIX", Type: "string", Opts: types.EnvVarOptions{Required: true}},
+				{Name: "TOKEN", Doc: "API token.", Type: "string", Opts: types.EnvVarOptions{Required: true}},
+				{Name: "NAME", Type: "string", Opts: types.EnvVarOptions{Default: "app"}},
+				{
+					Doc: "Database config.",
+					Children: []*types.EnvDocItem{
+						{Name: "DATABASE_HOST", Type: "string", Opts: types.EnvVarOptions{Required: true}},
+					},
+				},
+			},
+		},
+	}
+	var out strings.Builder
+	writeChangelogMarkdown(&out, diffSpecs(oldSpec, newSpec, map[string]string{"DB_HOST": "DATABASE_HOST"}))
+	expect := "# Environment variables changes\n" +
+		"\n## Breaking changes\n\n" +
+		" - `LISTEN_PORT` renamed from `PORT`\n" +
+		" - `TIMEOUT` lost default value `5s`\n" +
+		" - `PREFIX` became required\n" +
+		" - `TOKEN` added, required without default value - API token.\n" +
+		" - `DATABASE_HOST` renamed from `DB_HOST`\n" +
+		" - `LEGACY` removed\n" +
+		"\n## Added\n\n" +
+		" - `NAME` added\n" +
+		"\n## Changed\n\n" +
+		" - `DEBUG` default changed from `false` to `true`\n"
+	testutils.AssertError(t, out.String() == expect, "unexpected changelog:\n%s", out.String())
+
+	out.Reset()
+	writeChangelogMarkdown(&out, diffSpecs(oldSpec, oldSpec, nil))
+	testutils.AssertError(t, out.String() == "# Environment variables changes\n\nNo changes.\n",
+		"unexpected changelog:\n%s", out.String())
+
+	// variables without documentation are not renamed without hints
+	oldSpec = []*types.EnvScope{{Name: "Config", Vars: []*types.EnvDocItem{{Name: "DB_HOST", Type: "string"}}}}
+	newSpec = []*types.EnvScope{{Name: "Config", Vars: []*types.EnvDocItem{{Name: "CACHE_HOST", Type: "string"}}}}
+	out.Reset()
+	writeChangelogMarkdown(&out, diffSpecs(oldSpec, newSpec, nil))
+	expect = "# Environment variables changes\n" +
+		"\n## Breaking changes\n\n" +
+		" - `DB_HOST` removed\n" +
+		"\n## Added\n\n" +
+		" - `CACHE_HOST` added\n"
+	testutils.AssertError(t, out.String() == expect, "unexpected changelog:\n%s", out.String())
+}
+
+func TestVarChanges(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old, new types.EnvDocItem
+		details  string
+		breaking bool
+	}{
+		{
+			"required with default",
+			types.EnvDocItem{Type: "int"},
+			types.EnvDocItem{Type: "int", Opts: types.EnvVarOptions{Required: true, Default: "1"}},
+			"became required, got default value `1`", false,
+		},
+		{
+			"no longer required",
+			types.EnvDocItem{Type: "int", Opts: types.EnvVarOptions{Required: true}},
+			types.EnvDocItem{Type: "int"},
+			"is no longer required", false,
+		},
+		{
+			"type",
+			types.EnvDocItem{Type: "int"},
+			types.EnvDocItem{Type: "string"},
+			"type changed from `int` to `string`", true,
+		},
+		{
+			"non-empty",
+			types.EnvDocItem{Type: "string"},
+			types.EnvDocItem{Type: "string", Opts: types.EnvVarOptions{NonEmpty: true}},
+			"became non-empty", true,
+		},
+		{
+			"file",
+			types.EnvDocItem{Type: "string"},
+			types.EnvDocItem{Type: "string", Opts: types.EnvVarOptions{FromFile: true}},
+			"value became a file path", true,
+		},
+		{
+			"separator",
+			types.EnvDocItem{Type: "[]string"},
+			types.EnvDocItem{Type: "[]string", Opts: types.EnvVarOptions{Separator: ";"}},
+			"separator changed from `,` to `;`", true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			details, breaking := varChanges(&tc.old, &tc.new)
+			testutils.AssertError(t, strings.Join(details, ", ") == tc.details, "unexpected details: %v", details)
+			testutils.AssertError(t, breaking == tc.breaking, "expected breaking=%v", tc.breaking)
+		})
+	}
+}
+
+func TestDiffCommand(t *testing.T) {
+	t.Setenv("ENVDOC_CACHE", "off")
+	writeSpec := func(dir string, vars ...*types.EnvDocItem) string {
+		t.Helper()
+		var spec strings.Builder
+		scopes := []*types.EnvScope{{Name: "Config", Vars: vars}}
+		if err := render.NewRenderer(types.OutFormatJSON, true).Render(scopes, &spec); err != nil {
+			t.Fatalf("render spec: %v", err)
+		}
+		file := filepath.Join(dir, "env.json")
+		if err := os.WriteFile(file, []byte(spec.String()), 0o600); err != nil {
+			t.Fatalf("write spec: %v", err)
+		}
+		return file
+	}
+
+	t.Run("json files", func(t *testing.T) {
+		oldFile := writeSpec(t.TempDir(), &types.EnvDocItem{Name: "PORT", Type: "int"})
+		newFile := writeSpec(t.TempDir(), &types.EnvDocItem{Name: "HOST", Type: "string"})
+		var stdout, stderr strings.Builder
+		code := diffCommand([]string{"-format", "json", oldFile, newFile}, &stdout, &stderr)
+		testutils.AssertFatal(t, code == 0, "unexpected exit code %d: %s", code, stderr.String())
+		var log struct {
+			Breaking []specChange `json:"breaking"`
+			Changes  []specChange `json:"changes"`
+		}
+		err := json.Unmarshal([]byte(stdout.String()), &log)
+		testutils.AssertFatal(t, err == nil, "unmarshal changelog: %v", err)
+		testutils.AssertError(t, len(log.Breaking) == 1 && log.Breaking[0].Kind == changeRemoved &&
+			log.Breaking[0].Name == "PORT", "unexpected breaking changes: %+v", log.Breaking)
+		testutils.AssertError(t, len(log.Changes) == 1 && log.Changes[0].Kind == changeAdded &&
+			log.Changes[0].Name == "HOST", "unexpected changes: %+v", log.Changes)
+	})
+
+	t.Run("rename hint", func(t *testing.T) {
+		oldFile := writeSpec(t.TempDir(), &types.EnvDocItem{Name: "PORT", Type: "int"})
+		newFile := writeSpec(t.TempDir(), &types.EnvDocItem{Name: "LISTEN_PORT", Type: "int"})
+		var stdout, stderr strings.Builder
+		code := diffCommand([]string{"-rename", "PORT=LISTEN_PORT", oldFile, newFile}, &stdout, &stderr)
+		testutils.AssertFatal(t, code == 0, "unexpected exit code %d: %s", code, stderr.String())
+		testutils.AssertError(t, strings.Contains(stdout.String(), " - `LISTEN_PORT` renamed from `PORT`\n"),
+			"unexpected changelog:\n%s", stdout.String())
+
+		code = diffCommand([]string{"-rename", "PORT", oldFile, newFile}, &stdout, &stderr)
+		testutils.AssertError(t, code == 2, "unexpected exit code %d for invalid hint", code)
+	})
+
+	t.Run("base checkout", func(t *testing.T) {
+		writeSrc := func(dir, fields string) {
+			t.Helper()
+			content := "package a\n\ntype Config struct {\n" + fields + "}\n"
+			if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(content), 0o600); err != nil {
+				t.Fatalf("write source: %v", err)
+			}
+		}
+		base, dir := t.TempDir(), t.TempDir()
+		writeSrc(base, "\t// Port doc.\n\tPort int `env:\"PORT\" envDefault:\"80\"`\n")
+		writeSrc(dir, "\t// Port doc.\n\tPort int `env:\"PORT\"`\n")
+		var stdout, stderr strings.Builder
+		code := diffCommand([]string{"-base-ref", base, "-dir", dir, "-types", "*"}, &stdout, &stderr)
+		testutils.AssertFatal(t, code == 0, "unexpected exit code %d: %s", code, stderr.String())
+		testutils.AssertError(t, strings.Contains(stdout.String(), "## Breaking changes\n\n - `PORT` lost default value `80`\n"),
+			"unexpected changelog:\n%s", stdout.String())
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		code := diffCommand([]string{"old.json"}, &stdout, &stderr)
+		testutils.AssertError(t, code == 2, "unexpected exit code %d", code)
+	})
+}
@@ -26,17 +26,80 @@ Running go generate will generate the following Markdown file:
 	- `DEBUG` (default: `false`) - Debug mode enabled.
 
 By default envdoc generates documentation in Markdown format, but it
-can also generate plaintext or HTML.
+can also generate plaintext, HTML, dotenv, JSON, docker-compose, Dockerfile,
+systemd EnvironmentFile, shell script, man page, AsciiDoc or reStructuredText.
+
+envdoc can also run without go generate: in this mode types are selected
+by -types flag, or by envdoc go:generate directives in -dir directory
+and its subdirectories, e.g. `envdoc -dir ./... -format dotenv`.
+
+The generate command runs all envdoc go:generate directives of packages
+in one process and prints a summary of changed, unchanged and failed
+outputs, e.g. `envdoc generate ./...`; with -check flag it only reports
+outdated outputs.
+
+The validate command checks a dotenv file or the process environment
+against documented variables, e.g. `envdoc validate -spec env.json -env-file .env`:
+it exits with code 1 if required variables are missing, values can't be parsed
+or files of file variables don't exist.
+
+The diff command compares documented variables of two versions and prints
+a markdown or JSON changelog with breaking changes listed separately,
+e.g. `envdoc diff old.json new.json` or `envdoc diff -base-ref v1.2.0 -types '*'`.
+
+The drift command compares documented variables of sources with
+a hand-maintained dotenv file or markdown tables, e.g.
+`envdoc drift -against .env.example -types '*'`: it lists variables missing
+in code or in documentation and different defaults.
+
+The serve command starts a local HTTP server which renders HTML
+documentation with a search box and filters on every request,
+e.g. `envdoc serve -dir ./... -addr localhost:8080`.
 
 Options:
-  - `-output` - Output file name.
+  - `-output` - Output file name (`-` or empty for stdout), or `format=path` pair
+    (`format:edit=path` for edit mode), it may be repeated to generate multiple outputs.
   - `-type` - Type name to generate documentation for. Defaults for
     the next type after `go:generate` directive.
   - `-format` (default: `markdown`) - Set output format type, either `markdown`,
-    `plaintext` or `html`.
+    `plaintext`, `html`, `dotenv`, `json`, `compose`, `dockerfile`, `systemd`,
+    `shell`, `man`, `asciidoc`, `rst` or `go`.
   - `-all` - Generate documentation for all types in the file.
   - `-env-prefix` - Environment variable prefix.
   - `-no-styles` - Disable built-int CSS styles for HTML format.
+  - `-compose-service` (default: `app`) - Service name for compose format.
+  - `-man-section-only` - Render only ENVIRONMENT section for man format.
+  - `-go-package` - Package name for go format, default is the package of documented types.
+  - `-template` - Custom template file, see render.TemplateData for template data.
+  - `-source-link-template` - Link to variable declarations in Markdown and HTML
+    output, {file} and {line} placeholders are replaced with a path relative
+    to the git repository root and a line number.
+  - `-edit` - Replace content between envdoc:begin and envdoc:end markers
+    of the output file instead of overwriting it.
+  - `-marker-style` - Marker style for edit mode: `html`, `hash`, `slash`, `roff`
+    or `rst`, detected by output file name by default.
+  - `-section` - Section name for edit mode, it replaces content between
+    envdoc:begin:NAME and envdoc:end:NAME markers.
+  - `-check` - Check that output file is up to date without writing it,
+    also enabled by ENVDOC_CHECK environment variable.
+  - `-config` - Config file path, by default .envdoc.yaml is searched
+    in -dir directory and its parents.
+  - `-profile` - Generate only this profile of config file.
+  - `-concurrency` - Maximum number of directories parsed concurrently,
+    GOMAXPROCS by default.
+  - `-exclude` - Comma-separated globs of subdirectories to skip,
+    vendor, testdata, node_modules and dirs starting with '.' or '_'
+    are always skipped.
+  - `-skip-nested-modules` - Skip subdirectories with go.mod file.
+  - `-tags` - Comma-separated build tags to match files.
+  - `-goos`, `-goarch` - Target platform to match files, the current
+    GOOS and GOARCH by default.
+  - `-all-tags` - Document files of all build constraints and add
+    build constraint notes to variables.
+  - `-no-cache` - Disable on-disk cache of parsed files, ENVDOC_CACHE
+    environment variable sets cache directory or disables it with "off".
+  - `-watch` - Keep running and regenerate outputs on source changes.
+  - `-watch-interval` - Polling interval of watch mode, 500ms by default.
   - `-field-names` - Use field names instead of struct tags for variable names
     if tags are not set.
 */
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// envVar is a variable of dotenv file.
+type envVar struct {
+	Name  string
+	Value string
+	// Line is a line number of the variable in the file.
+	Line int
+}
+
+// parseDotenv parses dotenv file: NAME=value lines with optional `export`
+// prefix, double-quoted values with escapes, single-quoted literal values
+// and comments.
+func parseDotenv(r io.Reader) ([]envVar, error) {
+	var res []envVar
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		name, value, ok := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("line %d: invalid variable declaration %q", line, text)
+		}
+		value, err := parseDotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", line, name, err)
+		}
+		res = append(res, envVar{Name: name, Value: value, Line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dotenv: %w", err)
+	}
+	return res, nil
+}
+
+func parseDotenvValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '"':
+				return sb.String(), nil
+			case c == '\\' && i+1 < len(s):
+				i++
+				switch s[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				default:
+					sb.WriteByte(s[i])
+				}
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated quoted value")
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return s[1 : end+1], nil
+	default:
+		if i := strings.Index(s, " #"); i >= 0 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(s), nil
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	src := `# Environment Variables
+
+## Config
+# Port to listen on.
+PORT=8080
+export HOST = example.com # inline comment
+QUOTED="a \"b\"\nc"
+LITERAL='a \n b'
+EMPTY=
+`
+	vars, err := parseDotenv(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := []envVar{
+		{Name: "PORT", Value: "8080", Line: 5},
+		{Name: "HOST", Value: "example.com", Line: 6},
+		{Name: "QUOTED", Value: "a \"b\"\nc", Line: 7},
+		{Name: "LITERAL", Value: `a \n b`, Line: 8},
+		{Name: "EMPTY", Value: "", Line: 9},
+	}
+	if !reflect.DeepEqual(vars, expect) {
+		t.Fatalf("unexpected vars: %+v", vars)
+	}
+
+	for _, src := range []string{"PORT", "A B=1", `A="unterminated`, "A='unterminated"} {
+		if _, err := parseDotenv(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
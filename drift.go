@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/g4s8/envdoc/types"
+)
+
+// Kinds of documentation drift.
+const (
+	driftNotInCode      = "not in code"
+	driftNotDocumented  = "not documented"
+	driftDefaultDiffers = "default differs"
+)
+
+// driftIssue is a difference between variables of sources
+// and hand-maintained documentation.
+type driftIssue struct {
+	// Kind of the drift.
+	Kind string
+	// Name of the variable.
+	Name string
+	// Msg describes the difference, it's empty for missing variables.
+	Msg string
+	// Line of the variable in documentation file, or 0 if it's not documented.
+	Line int
+}
+
+// driftCommand compares variables of sources with hand-maintained
+// documentation: dotenv file or markdown tables. It returns exit code:
+// 0 if documentation is up to date, 1 if it drifted and 2 on errors.
+func driftCommand(args []string, stdout, stderr io.Writer) int {
+	var c Config
+	f := flag.NewFlagSet("envdoc drift", flag.ContinueOnError)
+	f.SetOutput(stderr)
+	var against string
+	f.StringVar(&against, "against", "", "Dotenv file or markdown file with tables of variables to compare with")
+	if err := c.parseFlags(f, args); err != nil {
+		return 2
+	}
+	if against == "" {
+		fmt.Fprintln(stderr, "Usage: envdoc drift -against .env.example [flags]")
+		return 2
+	}
+
+	documented, err := readDocumentedVars(against)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to read documentation: %v\n", err)
+		return 2
+	}
+	_, scopes, err := commandScopes(&c, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load spec: %v\n", err)
+		return 2
+	}
+
+	issues := driftIssues(flattenSpec(scopes), documented)
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		counts[issue.Kind]++
+		line := fmt.Sprintf("%-16s %s", issue.Kind, issue.Name)
+		if issue.Msg != "" {
+			line += ": " + issue.Msg
+		}
+		if issue.Line > 0 {
+			line += fmt.Sprintf(" (%s:%d)", against, issue.Line)
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	fmt.Fprintf(stdout, "%d %s, %d %s, %d defaults differ\n",
+		counts[driftNotInCode], driftNotInCode, counts[driftNotDocumented], driftNotDocumented,
+		counts[driftDefaultDiffers])
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// readDocumentedVars reads variables of markdown tables from .md files,
+// and variables of dotenv file otherwise.
+func readDocumentedVars(file string) ([]envVar, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".md", ".markdown":
+		return parseMarkdownTables(f)
+	default:
+		return parseDotenv(f)
+	}
+}
+
+// driftIssues compares variables of sources with documented variables:
+// it reports documented variables missing in code, variables of code
+// missing in documentation and different defaults. Defaults are compared
+// only if both code and documentation have them, since example values
+// of variables without defaults are usually placeholders.
+func driftIssues(vars []*types.EnvDocItem, documented []envVar) []driftIssue {
+	code := make(map[string]*types.EnvDocItem, len(vars))
+	for _, v := range vars {
+		code[v.Name] = v
+	}
+	docs := make(map[string]envVar, len(documented))
+	var res []driftIssue
+	for _, d := range documented {
+		if _, ok := docs[d.Name]; ok {
+			continue
+		}
+		docs[d.Name] = d
+		v, ok := code[d.Name]
+		switch {
+		case !ok:
+			res = append(res, driftIssue{Kind: driftNotInCode, Name: d.Name, Line: d.Line})
+		case v.Opts.Default != "" && d.Value != "" && v.Opts.Default != d.Value:
+			res = append(res, driftIssue{
+				Kind: driftDefaultDiffers,
+				Name: d.Name,
+				Msg:  fmt.Sprintf("code %q, documented %q", v.Opts.Default, d.Value),
+				Line: d.Line,
+			})
+		}
+	}
+	for _, v := range vars {
+		if _, ok := docs[v.Name]; !ok {
+			res = append(res, driftIssue{Kind: driftNotDocumented, Name: v.Name})
+		}
+	}
+	return res
+}
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseMarkdownTables parses variables of markdown tables. Variable name
+// is taken from the column with "name", "variable" or "env" header,
+// or the first column, default value from the column with "default" header.
+// Rows with cells which are not variable names are skipped.
+func parseMarkdownTables(r io.Reader) ([]envVar, error) {
+	var res []envVar
+	var header []string
+	nameCol, defCol := 0, -1
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(text, "|") {
+			header = nil
+			continue
+		}
+		cells := splitTableRow(text)
+		if header == nil {
+			header = cells
+			nameCol, defCol = tableColumns(header)
+			continue
+		}
+		if nameCol >= len(cells) {
+			continue
+		}
+		name := tableCell(cells[nameCol])
+		if !envNameRe.MatchString(name) {
+			continue
+		}
+		var value string
+		if defCol >= 0 && defCol < len(cells) {
+			value = tableCell(cells[defCol])
+		}
+		if value == "-" {
+			value = ""
+		}
+		res = append(res, envVar{Name: name, Value: value, Line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read markdown: %w", err)
+	}
+	return res, nil
+}
+
+func tableColumns(header []string) (nameCol, defCol int) {
+	nameCol, defCol = -1, -1
+	for i, h := range header {
+		h = strings.ToLower(tableCell(h))
+		switch {
+		case nameCol < 0 && (strings.Contains(h, "name") || strings.Contains(h, "variable") || h == "env"):
+			nameCol = i
+		case defCol < 0 && strings.Contains(h, "default"):
+			defCol = i
+		}
+	}
+	if nameCol < 0 {
+		nameCol = 0
+	}
+	return nameCol, defCol
+}
+
+func splitTableRow(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(strings.ReplaceAll(row, `\|`, "\x00"), "|")
+	for i, c := range cells {
+		cells[i] = strings.ReplaceAll(c, "\x00", "|")
+	}
+	return cells
+}
+
+func tableCell(s string) string {
+	return strings.Trim(strings.TrimSpace(s), "`*")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func TestParseMarkdownTables(t *testing.T) {
+	src := "# Config\n" +
+		"\n" +
+		"| Variable | Description | Default |\n" +
+		"|----------|-------------|---------|\n" +
+		"| `PORT` | Port to listen on. | `8080` |\n" +
+		"| **HOST** | Host \\| address. | - |\n" +
+		"| see below | not a variable | |\n" +
+		"\n" +
+		"| Option | Default |\n" +
+		"| --- | --- |\n" +
+		"| DEBUG | false |\n"
+	vars, err := parseMarkdownTables(strings.NewReader(src))
+	testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+	expect := []envVar{
+		{Name: "PORT", Value: "8080", Line: 5},
+		{Name: "HOST", Value: "", Line: 6},
+		{Name: "DEBUG", Value: "false", Line: 11},
+	}
+	testutils.AssertError(t, reflect.DeepEqual(vars, expect), "unexpected vars: %+v", vars)
+}
+
+func TestDriftIssues(t *testing.T) {
+	vars := []*types.EnvDocItem{
+		{Name: "PORT", Opts: types.EnvVarOptions{Default: "8080"}},
+		{Name: "HOST", Opts: types.EnvVarOptions{Default: "localhost"}},
+		{Name: "DEBUG", Opts: types.EnvVarOptions{Default: "false"}},
+		{Name: "TOKEN"},
+		{Name: "PREFIX"},
+	}
+	documented := []envVar{
+		{Name: "PORT", Value: "8080", Line: 1},
+		{Name: "HOST", Value: "", Line: 2},
+		{Name: "DEBUG", Value: "true", Line: 3},
+		{Name: "TOKEN", Value: "changeme", Line: 4},
+		{Name: "OLD_VAR", Value: "1", Line: 5},
+	}
+	issues := driftIssues(vars, documented)
+	expect := []driftIssue{
+		{Kind: driftDefaultDiffers, Name: "DEBUG", Msg: `code "false", documented "true"`, Line: 3},
+		{Kind: driftNotInCode, Name: "OLD_VAR", Line: 5},
+		{Kind: driftNotDocumented, Name: "PREFIX"},
+	}
+	testutils.AssertError(t, reflect.DeepEqual(issues, expect), "unexpected issues: %+v", issues)
+}
+
+func TestDriftCommand(t *testing.T) {
+	t.Setenv("ENVDOC_CACHE", "off")
+	dir := t.TempDir()
+	src := "package a\n\ntype Config struct {\n\t// Port doc.\n\tPort int `env:\"PORT\" envDefault:\"80\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	run := func(env string) (int, string) {
+		t.Helper()
+		envFile := filepath.Join(dir, ".env.example")
+		if err := os.WriteFile(envFile, []byte(env), 0o600); err != nil {
+			t.Fatalf("write env file: %v", err)
+		}
+		var stdout, stderr strings.Builder
+		code := driftCommand([]string{"-against", envFile, "-dir", dir, "-types", "*"}, &stdout, &stderr)
+		return code, stdout.String() + stderr.String()
+	}
+
+	code, out := run("PORT=80\n")
+	testutils.AssertError(t, code == 0, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, out == "0 not in code, 0 not documented, 0 defaults differ\n", "unexpected output: %s", out)
+
+	code, out = run("PORT=8080\nHOST=localhost\n")
+	testutils.AssertError(t, code == 1, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, strings.Contains(out, `default differs  PORT: code "80", documented "8080"`) &&
+		strings.Contains(out, "not in code      HOST ("), "unexpected output: %s", out)
+
+	var stdout, stderr strings.Builder
+	code = driftCommand([]string{"-dir", dir}, &stdout, &stderr)
+	testutils.AssertError(t, code == 2, "unexpected exit code %d", code)
+}
@@ -2,9 +2,12 @@ package edit
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode"
 )
 
 const (
@@ -12,22 +15,188 @@ const (
 	MarkerEnd   = "<!--envdoc:end-->"
 )
 
+// MarkerStyle defines how begin and end markers are written in a host file,
+// usually it's a comment syntax of the file format.
+type MarkerStyle struct {
+	// Open is a text before marker name.
+	Open string
+	// Close is a text after marker name.
+	Close string
+	// Blank separates markers from generated content with blank lines,
+	// it's required by formats where comment block continues until blank line.
+	Blank bool
+}
+
+var (
+	// MarkerStyleHTML is a marker style for HTML comments: <!--envdoc:begin-->.
+	MarkerStyleHTML = MarkerStyle{Open: "<!--", Close: "-->"}
+	// MarkerStyleRoff is a marker style for roff comments: .\" envdoc:begin.
+	MarkerStyleRoff = MarkerStyle{Open: `.\" `}
+	// MarkerStyleSlash is a marker style for double-slash comments: // envdoc:begin.
+	MarkerStyleSlash = MarkerStyle{Open: "// "}
+	// MarkerStyleRST is a marker style for reStructuredText comments: .. envdoc:begin.
+	MarkerStyleRST = MarkerStyle{Open: ".. ", Blank: true}
+	// MarkerStyleHash is a marker style for hash comments: # envdoc:begin.
+	MarkerStyleHash = MarkerStyle{Open: "# "}
+)
+
+// markerStyleNames maps marker style names to styles.
+var markerStyleNames = map[string]MarkerStyle{
+	"html":  MarkerStyleHTML,
+	"roff":  MarkerStyleRoff,
+	"slash": MarkerStyleSlash,
+	"rst":   MarkerStyleRST,
+	"hash":  MarkerStyleHash,
+}
+
+// ParseMarkerStyle returns marker style by name:
+// html, roff, slash, rst or hash.
+func ParseMarkerStyle(name string) (MarkerStyle, error) {
+	style, ok := markerStyleNames[strings.ToLower(name)]
+	if !ok {
+		return MarkerStyle{}, fmt.Errorf("unknown marker style: %q", name)
+	}
+	return style, nil
+}
+
+// markerStyleExts maps host file extensions to marker styles.
+var markerStyleExts = map[string]MarkerStyle{
+	".md":         MarkerStyleHTML,
+	".markdown":   MarkerStyleHTML,
+	".html":       MarkerStyleHTML,
+	".htm":        MarkerStyleHTML,
+	".xml":        MarkerStyleHTML,
+	".yaml":       MarkerStyleHash,
+	".yml":        MarkerStyleHash,
+	".env":        MarkerStyleHash,
+	".sh":         MarkerStyleHash,
+	".bash":       MarkerStyleHash,
+	".toml":       MarkerStyleHash,
+	".conf":       MarkerStyleHash,
+	".properties": MarkerStyleHash,
+	".dockerfile": MarkerStyleHash,
+	".go":         MarkerStyleSlash,
+	".adoc":       MarkerStyleSlash,
+	".asciidoc":   MarkerStyleSlash,
+	".rst":        MarkerStyleRST,
+	".man":        MarkerStyleRoff,
+	".roff":       MarkerStyleRoff,
+}
+
+// markerStyleFiles maps host file names without known extension to marker styles.
+var markerStyleFiles = map[string]MarkerStyle{
+	"dockerfile": MarkerStyleHash,
+	"makefile":   MarkerStyleHash,
+}
+
+// MarkerStyleForFile detects marker style by host file name,
+// it returns false if the file type is unknown.
+// Dotenv files like .env.example are detected by .env prefix.
+func MarkerStyleForFile(path string) (MarkerStyle, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	if name == ".env" || strings.HasPrefix(name, ".env.") {
+		return MarkerStyleHash, true
+	}
+	ext := filepath.Ext(name)
+	if style, ok := markerStyleExts[ext]; ok {
+		return style, true
+	}
+	// man pages: envdoc.1, envdoc.7, etc.
+	if len(ext) == 2 && ext[1] >= '1' && ext[1] <= '9' {
+		return MarkerStyleRoff, true
+	}
+	style, ok := markerStyleFiles[name]
+	return style, ok
+}
+
+// Begin returns begin marker.
+func (s MarkerStyle) Begin() string {
+	return s.Open + "envdoc:begin" + s.Close
+}
+
+// End returns end marker.
+func (s MarkerStyle) End() string {
+	return s.Open + "envdoc:end" + s.Close
+}
+
+// BeginSection returns begin marker of named section: envdoc:begin:NAME.
+// Empty name returns unnamed begin marker.
+func (s MarkerStyle) BeginSection(name string) string {
+	if name == "" {
+		return s.Begin()
+	}
+	return s.Open + "envdoc:begin:" + name + s.Close
+}
+
+// EndSection returns end marker of named section: envdoc:end:NAME.
+// Empty name returns unnamed end marker.
+func (s MarkerStyle) EndSection(name string) string {
+	if name == "" {
+		return s.End()
+	}
+	return s.Open + "envdoc:end:" + name + s.Close
+}
+
+// ValidateSectionName checks that section name can be used in markers:
+// it may contain only letters, digits, '_', '-' and '.'.
+func ValidateSectionName(name string) error {
+	if name == "" {
+		return errors.New("empty section name")
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			return fmt.Errorf("invalid character %q in section name %q", r, name)
+		}
+	}
+	return nil
+}
+
+// EditorOption configures the Editor.
+type EditorOption func(*Editor)
+
+// WithMarkerStyle sets marker style for the Editor, HTML comments are used by default.
+func WithMarkerStyle(style MarkerStyle) EditorOption {
+	return func(e *Editor) {
+		e.style = style
+	}
+}
+
+// WithSection sets section name for the Editor, so it replaces only content
+// between envdoc:begin:NAME and envdoc:end:NAME markers.
+// Unnamed markers are used by default.
+func WithSection(name string) EditorOption {
+	return func(e *Editor) {
+		e.section = name
+	}
+}
+
 // Editor handles in-place editing of files with marker-based content replacement
 type Editor struct {
 	filePath string
+	style    MarkerStyle
+	section  string
 }
 
 // NewEditor creates a new Editor for the specified file path
-func NewEditor(filePath string) *Editor {
-	return &Editor{
+func NewEditor(filePath string, opts ...EditorOption) *Editor {
+	e := &Editor{
 		filePath: filePath,
+		style:    MarkerStyleHTML,
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	return e
 }
 
 // ReplaceSection replaces the content between markers with newContent.
 // If the file doesn't exist, it creates a new file with markers wrapping the content.
 // If the file exists, it finds the markers and replaces only the content between them.
 func (e *Editor) ReplaceSection(newContent []byte) error {
+	if err := e.validateSection(); err != nil {
+		return err
+	}
+
 	// Check if file exists
 	_, err := os.Stat(e.filePath)
 	if os.IsNotExist(err) {
@@ -40,45 +209,109 @@ func (e *Editor) ReplaceSection(newContent []byte) error {
 	return e.replaceInExistingFile(newContent)
 }
 
+// Preview returns current file content and the content after replacing
+// the section with newContent, without writing anything.
+// Current content is nil if the file doesn't exist.
+func (e *Editor) Preview(newContent []byte) (current, updated []byte, err error) {
+	if err := e.validateSection(); err != nil {
+		return nil, nil, err
+	}
+
+	current, err = os.ReadFile(e.filePath)
+	if os.IsNotExist(err) {
+		return nil, e.contentWithMarkers(newContent), nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	updated, err = e.replaceContent(current, newContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, updated, nil
+}
+
+func (e *Editor) validateSection() error {
+	if e.section == "" {
+		return nil
+	}
+	return ValidateSectionName(e.section)
+}
+
 func (e *Editor) createFileWithMarkers(content []byte) error {
 	dir := filepath.Dir(e.filePath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
+	return e.atomicWrite(e.contentWithMarkers(content))
+}
+
+// contentWithMarkers returns content of new file with markers wrapping the content.
+func (e *Editor) contentWithMarkers(content []byte) []byte {
 	var buf bytes.Buffer
-	buf.WriteString(MarkerBegin)
+	buf.WriteString(e.style.BeginSection(e.section))
+	e.writeContent(&buf, content)
+	buf.WriteString(e.style.EndSection(e.section))
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// writeContent writes generated content after begin marker.
+func (e *Editor) writeContent(buf *bytes.Buffer, content []byte) {
 	buf.WriteString("\n")
+	if e.style.Blank {
+		buf.WriteString("\n")
+	}
 	buf.Write(content)
 	if len(content) > 0 && content[len(content)-1] != '\n' {
 		buf.WriteString("\n")
 	}
-	buf.WriteString(MarkerEnd)
-	buf.WriteString("\n")
+	if e.style.Blank && !bytes.HasSuffix(content, []byte("\n\n")) {
+		buf.WriteString("\n")
+	}
+}
 
-	return e.atomicWrite(buf.Bytes())
+// findMarker returns positions of marker in content. Markers of styles without
+// closing text must be followed by whitespace or end of content, so "# envdoc:begin"
+// doesn't match named "# envdoc:begin:server" marker.
+func findMarker(content []byte, marker string, closed bool) []int {
+	var res []int
+	for offset := 0; ; {
+		idx := bytes.Index(content[offset:], []byte(marker))
+		if idx == -1 {
+			return res
+		}
+		idx += offset
+		offset = idx + len(marker)
+		if !closed && offset < len(content) && !unicode.IsSpace(rune(content[offset])) {
+			continue
+		}
+		res = append(res, idx)
+	}
 }
 
-func validateMarkers(content []byte, beginIdx, endIdx int) error {
-	if beginIdx == -1 && endIdx == -1 {
-		return fmt.Errorf("no markers found in file: missing both %s and %s", MarkerBegin, MarkerEnd)
+func validateMarkers(begin, end string, beginIdx, endIdx []int) error {
+	if len(beginIdx) == 0 && len(endIdx) == 0 {
+		return fmt.Errorf("no markers found in file: missing both %s and %s", begin, end)
 	}
-	if beginIdx == -1 {
-		return fmt.Errorf("missing begin marker: %s", MarkerBegin)
+	if len(beginIdx) == 0 {
+		return fmt.Errorf("missing begin marker: %s", begin)
 	}
-	if endIdx == -1 {
-		return fmt.Errorf("missing end marker: %s", MarkerEnd)
+	if len(endIdx) == 0 {
+		return fmt.Errorf("missing end marker: %s", end)
 	}
-	if beginIdx >= endIdx {
+	if beginIdx[0] >= endIdx[0] {
 		return fmt.Errorf("markers in wrong order: begin marker must come before end marker")
 	}
 
 	// Check for duplicate markers
-	if bytes.Count(content, []byte(MarkerBegin)) > 1 {
-		return fmt.Errorf("duplicate begin markers found: %s appears more than once", MarkerBegin)
+	if len(beginIdx) > 1 {
+		return fmt.Errorf("duplicate begin markers found: %s appears more than once", begin)
 	}
-	if bytes.Count(content, []byte(MarkerEnd)) > 1 {
-		return fmt.Errorf("duplicate end markers found: %s appears more than once", MarkerEnd)
+	if len(endIdx) > 1 {
+		return fmt.Errorf("duplicate end markers found: %s appears more than once", end)
 	}
 
 	return nil
@@ -90,32 +323,62 @@ func (e *Editor) replaceInExistingFile(newContent []byte) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	beginIdx := bytes.Index(existingContent, []byte(MarkerBegin))
-	endIdx := bytes.Index(existingContent, []byte(MarkerEnd))
-
-	if err := validateMarkers(existingContent, beginIdx, endIdx); err != nil {
+	content, err := e.replaceContent(existingContent, newContent)
+	if err != nil {
 		return err
 	}
 
-	var buf bytes.Buffer
+	return e.atomicWrite(content)
+}
 
-	buf.Write(existingContent[:beginIdx])
-	buf.WriteString(MarkerBegin)
-	buf.WriteString("\n")
-	buf.Write(newContent)
-	if len(newContent) > 0 && newContent[len(newContent)-1] != '\n' {
-		buf.WriteString("\n")
+// replaceContent replaces the section of existingContent with newContent.
+func (e *Editor) replaceContent(existingContent, newContent []byte) ([]byte, error) {
+	begin, end := e.style.BeginSection(e.section), e.style.EndSection(e.section)
+	closed := e.style.Close != ""
+	beginIdx := findMarker(existingContent, begin, closed)
+	endIdx := findMarker(existingContent, end, closed)
+
+	if err := validateMarkers(begin, end, beginIdx, endIdx); err != nil {
+		return nil, err
 	}
-	endMarkerStart := endIdx
-	buf.Write(existingContent[endMarkerStart:])
 
-	return e.atomicWrite(buf.Bytes())
+	var buf bytes.Buffer
+
+	buf.Write(existingContent[:beginIdx[0]])
+	buf.WriteString(begin)
+	e.writeContent(&buf, newContent)
+	buf.Write(existingContent[endIdx[0]:])
+
+	return buf.Bytes(), nil
 }
 
-// atomicWrite writes content to a temp file then renames it to the target path
-// This prevents corruption if the write fails partway through
+// atomicWrite writes content to the file atomically, see WriteFileAtomic.
 func (e *Editor) atomicWrite(content []byte) error {
-	dir := filepath.Dir(e.filePath)
+	return WriteFileAtomic(e.filePath, content, 0o644)
+}
+
+// WriteFileAtomic writes content to a temp file then renames it to the target path.
+// This prevents corruption if the write fails partway through, and readers never see
+// partially written file. Like os.WriteFile, it creates new file with perm permissions
+// and keeps permissions of existing file. Symlinks are kept: the target file is
+// replaced, and targets which are not regular files, e.g. /dev/stdout, are written
+// in place without a temp file.
+func WriteFileAtomic(path string, content []byte, perm os.FileMode) error {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			// target can't be resolved, e.g. it's a pipe or it doesn't exist yet
+			return writeFile(path, content, perm)
+		}
+		path = target
+	}
+	if info, err := os.Stat(path); err == nil {
+		if !info.Mode().IsRegular() {
+			return writeFile(path, content, perm)
+		}
+		perm = info.Mode().Perm()
+	}
+	dir := filepath.Dir(path)
 	tmpFile, err := os.CreateTemp(dir, ".envdoc-*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
@@ -133,14 +396,27 @@ func (e *Editor) atomicWrite(content []byte) error {
 		return fmt.Errorf("failed to write temp file: %w", err)
 	}
 
+	if err := tmpFile.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set temp file permissions: %w", err)
+	}
+
 	if err := tmpFile.Close(); err != nil {
 		return fmt.Errorf("failed to close temp file: %w", err)
 	}
 
-	if err := os.Rename(tmpPath, e.filePath); err != nil {
+	if err := os.Rename(tmpPath, path); err != nil {
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
 	tmpFile = nil
 	return nil
 }
+
+// writeFile writes content to the file in place, it's used for files
+// which can't be replaced by rename.
+func writeFile(path string, content []byte, perm os.FileMode) error {
+	if err := os.WriteFile(path, content, perm); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
@@ -645,3 +645,411 @@ Footer.
 		t.Errorf("Expected temp file creation error, got: %v", err)
 	}
 }
+
+func TestEditor_ReplaceSection_RoffMarkers(t *testing.T) {
+	// Create temp directory
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "app.1")
+
+	// Create initial man page with roff comment markers
+	initialContent := `.TH APP 1
+.SH NAME
+app \- example
+.\" envdoc:begin
+old
+.\" envdoc:end
+.SH SEE ALSO
+`
+	if err := os.WriteFile(filePath, []byte(initialContent), 0o644); err != nil {
+		t.Fatalf("Failed to create initial file: %v", err)
+	}
+
+	// Create editor with roff markers
+	editor := NewEditor(filePath, WithMarkerStyle(MarkerStyleRoff))
+	if err := editor.ReplaceSection([]byte(".SH ENVIRONMENT\n")); err != nil {
+		t.Fatalf("ReplaceSection failed: %v", err)
+	}
+
+	result, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read result file: %v", err)
+	}
+
+	expected := `.TH APP 1
+.SH NAME
+app \- example
+.\" envdoc:begin
+.SH ENVIRONMENT
+.\" envdoc:end
+.SH SEE ALSO
+`
+	if string(result) != expected {
+		t.Errorf("Unexpected result:\nGot:\n%s\nExpected:\n%s", result, expected)
+	}
+}
+
+func TestEditor_ReplaceSection_RSTMarkers(t *testing.T) {
+	// Create temp directory
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "config.rst")
+
+	// Create editor with rST markers, file doesn't exist yet
+	editor := NewEditor(filePath, WithMarkerStyle(MarkerStyleRST))
+	if err := editor.ReplaceSection([]byte("Title\n=====\n")); err != nil {
+		t.Fatalf("ReplaceSection failed: %v", err)
+	}
+	// Replace it again to check that blank lines are not accumulated
+	if err := editor.ReplaceSection([]byte("Title\n=====\n")); err != nil {
+		t.Fatalf("ReplaceSection failed: %v", err)
+	}
+
+	result, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read result file: %v", err)
+	}
+
+	expected := ".. envdoc:begin\n\nTitle\n=====\n\n.. envdoc:end\n"
+	if string(result) != expected {
+		t.Errorf("Unexpected result:\nGot:\n%q\nExpected:\n%q", result, expected)
+	}
+}
+
+func TestEditor_ReplaceSection_HashMarkers(t *testing.T) {
+	// Create temp directory
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, ".env.example")
+
+	initialContent := "# Local settings\nDEBUG=true\n# envdoc:begin\nOLD=1\n# envdoc:end\nLOCAL=1\n"
+	if err := os.WriteFile(filePath, []byte(initialContent), 0o644); err != nil {
+		t.Fatalf("Failed to write initial file: %v", err)
+	}
+
+	style, ok := MarkerStyleForFile(filePath)
+	if !ok {
+		t.Fatalf("Marker style is not detected for %s", filePath)
+	}
+	editor := NewEditor(filePath, WithMarkerStyle(style))
+	if err := editor.ReplaceSection([]byte("# Server port\nPORT=\"8080\"\n")); err != nil {
+		t.Fatalf("ReplaceSection failed: %v", err)
+	}
+
+	result, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read result file: %v", err)
+	}
+
+	expected := "# Local settings\nDEBUG=true\n# envdoc:begin\n# Server port\nPORT=\"8080\"\n# envdoc:end\nLOCAL=1\n"
+	if string(result) != expected {
+		t.Errorf("Unexpected result:\nGot:\n%q\nExpected:\n%q", result, expected)
+	}
+}
+
+func TestMarkerStyleForFile(t *testing.T) {
+	for _, tc := range []struct {
+		path  string
+		style MarkerStyle
+		ok    bool
+	}{
+		{"README.md", MarkerStyleHTML, true},
+		{"docs/index.html", MarkerStyleHTML, true},
+		{"values.yaml", MarkerStyleHash, true},
+		{"compose.yml", MarkerStyleHash, true},
+		{".env", MarkerStyleHash, true},
+		{".env.example", MarkerStyleHash, true},
+		{"prod.env", MarkerStyleHash, true},
+		{"entrypoint.sh", MarkerStyleHash, true},
+		{"Dockerfile", MarkerStyleHash, true},
+		{"config.go", MarkerStyleSlash, true},
+		{"README.adoc", MarkerStyleSlash, true},
+		{"README.rst", MarkerStyleRST, true},
+		{"man/app.1", MarkerStyleRoff, true},
+		{"app.7", MarkerStyleRoff, true},
+		{"notes.txt", MarkerStyle{}, false},
+		{"config.json", MarkerStyle{}, false},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			style, ok := MarkerStyleForFile(tc.path)
+			if ok != tc.ok {
+				t.Fatalf("Expected ok=%v, got %v", tc.ok, ok)
+			}
+			if style != tc.style {
+				t.Errorf("Expected style %+v, got %+v", tc.style, style)
+			}
+		})
+	}
+}
+
+func TestParseMarkerStyle(t *testing.T) {
+	style, err := ParseMarkerStyle("hash")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if style != MarkerStyleHash {
+		t.Errorf("Expected hash style, got %+v", style)
+	}
+	if _, err := ParseMarkerStyle("unknown"); err == nil {
+		t.Error("Expected error for unknown marker style")
+	}
+}
+
+func TestEditor_ReplaceSection_NamedSections(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		file     string
+		style    MarkerStyle
+		initial  string
+		expected string
+	}{
+		{
+			name:  "html",
+			file:  "README.md",
+			style: MarkerStyleHTML,
+			initial: "# README\n" +
+				"<!--envdoc:begin:server-->\nold server\n<!--envdoc:end:server-->\n" +
+				"<!--envdoc:begin:client-->\nold client\n<!--envdoc:end:client-->\n",
+			expected: "# README\n" +
+				"<!--envdoc:begin:server-->\nnew server\n<!--envdoc:end:server-->\n" +
+				"<!--envdoc:begin:client-->\nold client\n<!--envdoc:end:client-->\n",
+		},
+		{
+			name:  "hash",
+			file:  "values.yaml",
+			style: MarkerStyleHash,
+			initial: "# envdoc:begin:server2\nold server2\n# envdoc:end:server2\n" +
+				"# envdoc:begin\nold unnamed\n# envdoc:end\n" +
+				"# envdoc:begin:server\nold server\n# envdoc:end:server\n",
+			expected: "# envdoc:begin:server2\nold server2\n# envdoc:end:server2\n" +
+				"# envdoc:begin\nold unnamed\n# envdoc:end\n" +
+				"# envdoc:begin:server\nnew server\n# envdoc:end:server\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(filePath, []byte(tc.initial), 0o644); err != nil {
+				t.Fatalf("Failed to write initial file: %v", err)
+			}
+
+			editor := NewEditor(filePath, WithMarkerStyle(tc.style), WithSection("server"))
+			if err := editor.ReplaceSection([]byte("new server\n")); err != nil {
+				t.Fatalf("ReplaceSection failed: %v", err)
+			}
+
+			result, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read result file: %v", err)
+			}
+			if string(result) != tc.expected {
+				t.Errorf("Unexpected result:\nGot:\n%q\nExpected:\n%q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestEditor_ReplaceSection_NamedSectionErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "README.md")
+	content := "<!--envdoc:begin-->\nunnamed\n<!--envdoc:end-->\n" +
+		"<!--envdoc:begin:server-->\nserver\n<!--envdoc:end:server-->\n" +
+		"<!--envdoc:begin:server-->\nserver\n<!--envdoc:end:server-->\n"
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write initial file: %v", err)
+	}
+
+	t.Run("missing section", func(t *testing.T) {
+		err := NewEditor(filePath, WithSection("client")).ReplaceSection([]byte("new"))
+		if err == nil || !strings.Contains(err.Error(), "<!--envdoc:begin:client-->") {
+			t.Errorf("Expected missing markers error, got: %v", err)
+		}
+	})
+	t.Run("duplicate section", func(t *testing.T) {
+		err := NewEditor(filePath, WithSection("server")).ReplaceSection([]byte("new"))
+		if err == nil || !strings.Contains(err.Error(), "duplicate begin markers") {
+			t.Errorf("Expected duplicate markers error, got: %v", err)
+		}
+	})
+	t.Run("invalid name", func(t *testing.T) {
+		err := NewEditor(filePath, WithSection("server-->")).ReplaceSection([]byte("new"))
+		if err == nil || !strings.Contains(err.Error(), "section name") {
+			t.Errorf("Expected invalid section name error, got: %v", err)
+		}
+	})
+	t.Run("create with named markers", func(t *testing.T) {
+		newPath := filepath.Join(tmpDir, "new.md")
+		if err := NewEditor(newPath, WithSection("server")).ReplaceSection([]byte("new\n")); err != nil {
+			t.Fatalf("ReplaceSection failed: %v", err)
+		}
+		result, err := os.ReadFile(newPath)
+		if err != nil {
+			t.Fatalf("Failed to read result file: %v", err)
+		}
+		expected := "<!--envdoc:begin:server-->\nnew\n<!--envdoc:end:server-->\n"
+		if string(result) != expected {
+			t.Errorf("Unexpected result:\nGot:\n%q\nExpected:\n%q", result, expected)
+		}
+	})
+}
+
+func TestEditor_Preview(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "README.md")
+
+	t.Run("missing file", func(t *testing.T) {
+		current, updated, err := NewEditor(filePath).Preview([]byte("new\n"))
+		if err != nil {
+			t.Fatalf("Preview failed: %v", err)
+		}
+		if current != nil {
+			t.Errorf("Expected nil current content, got: %q", current)
+		}
+		expected := "<!--envdoc:begin-->\nnew\n<!--envdoc:end-->\n"
+		if string(updated) != expected {
+			t.Errorf("Unexpected result:\nGot:\n%q\nExpected:\n%q", updated, expected)
+		}
+		if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+			t.Errorf("Preview must not create the file")
+		}
+	})
+	t.Run("existing file", func(t *testing.T) {
+		initial := "# README\n<!--envdoc:begin-->\nold\n<!--envdoc:end-->\n"
+		if err := os.WriteFile(filePath, []byte(initial), 0o644); err != nil {
+			t.Fatalf("Failed to write initial file: %v", err)
+		}
+		current, updated, err := NewEditor(filePath).Preview([]byte("new\n"))
+		if err != nil {
+			t.Fatalf("Preview failed: %v", err)
+		}
+		if string(current) != initial {
+			t.Errorf("Unexpected current content: %q", current)
+		}
+		expected := "# README\n<!--envdoc:begin-->\nnew\n<!--envdoc:end-->\n"
+		if string(updated) != expected {
+			t.Errorf("Unexpected result:\nGot:\n%q\nExpected:\n%q", updated, expected)
+		}
+		result, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("Failed to read result file: %v", err)
+		}
+		if string(result) != initial {
+			t.Errorf("Preview must not change the file")
+		}
+	})
+	t.Run("missing markers", func(t *testing.T) {
+		if err := os.WriteFile(filePath, []byte("# README\n"), 0o644); err != nil {
+			t.Fatalf("Failed to write initial file: %v", err)
+		}
+		if _, _, err := NewEditor(filePath).Preview([]byte("new\n")); err == nil {
+			t.Errorf("Expected error for missing markers")
+		}
+	})
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "out.md")
+
+	if err := WriteFileAtomic(filePath, []byte("first\n"), 0o640); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o640 {
+		t.Errorf("Unexpected permissions of new file: %v", info.Mode().Perm())
+	}
+
+	if err := os.Chmod(filePath, 0o600); err != nil {
+		t.Fatalf("Failed to chmod file: %v", err)
+	}
+	if err := WriteFileAtomic(filePath, []byte("second\n"), 0o644); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	result, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read result file: %v", err)
+	}
+	if string(result) != "second\n" {
+		t.Errorf("Unexpected content: %q", result)
+	}
+	info, err = os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("Permissions of existing file must be preserved, got: %v", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Temp files must be removed, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_Symlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Symlinks require privileges on Windows")
+	}
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "target.md")
+	if err := os.WriteFile(target, []byte("old"), 0o600); err != nil {
+		t.Fatalf("Failed to write target file: %v", err)
+	}
+	link := filepath.Join(tmpDir, "link.md")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if err := WriteFileAtomic(link, []byte("new"), 0o644); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	// the link must be kept and the target must be updated
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Failed to stat link: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Symlink was replaced by regular file")
+	}
+	result, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read target file: %v", err)
+	}
+	if string(result) != "new" {
+		t.Errorf("Expected target content %q, got %q", "new", result)
+	}
+	info, err = os.Stat(target)
+	if err != nil {
+		t.Fatalf("Failed to stat target file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected target permissions to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileAtomic_NotRegular(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("No /dev/null symlinks on Windows")
+	}
+	// device files like /dev/stdout are written in place
+	link := filepath.Join(t.TempDir(), "null")
+	if err := os.Symlink(os.DevNull, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := WriteFileAtomic(link, []byte("content"), 0o644); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Failed to stat link: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Symlink was replaced by regular file")
+	}
+	if info, err := os.Stat(os.DevNull); err != nil || info.Mode().IsRegular() {
+		t.Errorf("%s was replaced by regular file", os.DevNull)
+	}
+}
@@ -1,8 +1,8 @@
-package main
+package envdoc
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"strings"
 
 	"github.com/g4s8/envdoc/ast"
@@ -10,23 +10,33 @@ import (
 	"github.com/g4s8/envdoc/types"
 )
 
+// Resolver resolves type references of fields.
 type Resolver interface {
 	Resolve(f *ast.FileSpec, ref *ast.FieldTypeRef) *ast.TypeSpec
 }
 
+// ConverterOpts are options of field tags and names conversion.
 type ConverterOpts struct {
 	EnvPrefix       string
 	TagName         string
 	TagDefault      string
 	RequiredIfNoDef bool
 	UseFieldNames   bool
+	// BuildConstraints adds build constraints of source files to doc items.
+	BuildConstraints bool
+	// Warnings is an output for conversion warnings, they are discarded if it's nil.
+	Warnings io.Writer
+	// Logger logs debug messages of conversion if it's not nil.
+	Logger debug.Logger
 }
 
+// Converter converts parsed types to documentation scopes.
 type Converter struct {
 	target types.TargetType
 	opts   ConverterOpts
 }
 
+// NewConverter creates converter of field tags of target env library.
 func NewConverter(target types.TargetType, opts ConverterOpts) *Converter {
 	return &Converter{
 		target: target,
@@ -34,16 +44,17 @@ func NewConverter(target types.TargetType, opts ConverterOpts) *Converter {
 	}
 }
 
+// ScopesFromFiles converts exported types of exported files to scopes.
 func (c *Converter) ScopesFromFiles(res Resolver, files []*ast.FileSpec) []*types.EnvScope {
 	var scopes []*types.EnvScope
 	for _, f := range files {
 		if !f.Export {
-			debug.Logf("# CONV: skip file %q\n", f.Name)
+			c.logf("# CONV: skip file %q\n", f.Name)
 			continue
 		}
 		for _, t := range f.Types {
 			if !t.Export {
-				debug.Logf("# CONV: skip type %q\n", t.Name)
+				c.logf("# CONV: skip type %q\n", t.Name)
 				continue
 			}
 			scopes = append(scopes, c.ScopeFromType(res, f, t))
@@ -52,21 +63,27 @@ func (c *Converter) ScopesFromFiles(res Resolver, files []*ast.FileSpec) []*type
 	return scopes
 }
 
+// ScopeFromType converts type to documentation scope.
 func (c *Converter) ScopeFromType(res Resolver, file *ast.FileSpec, t *ast.TypeSpec) *types.EnvScope {
 	scope := &types.EnvScope{
 		Name: t.Name,
+		Pkg:  file.Pkg,
 		Doc:  t.Doc,
+		File: t.Pos.File,
+		Line: t.Pos.Line,
 	}
 	scope.Vars = c.DocItemsFromFields(res, file, c.opts.EnvPrefix, t.Fields)
-	debug.Logf("# CONV: found scope %q\n", scope.Name)
+	c.logf("# CONV: found scope %q at %s\n", scope.Name, t.Pos)
 	return scope
 }
 
+// DocItemsFromFields converts fields to doc items, fields of embedded
+// types are inlined.
 func (c *Converter) DocItemsFromFields(res Resolver, file *ast.FileSpec, prefix string, fields []*ast.FieldSpec) []*types.EnvDocItem {
 	var items []*types.EnvDocItem
 	for _, f := range fields {
-		debug.Logf("\t# CONV: field [%s] type=%s flen=%d\n",
-			strings.Join(f.Names, ","), f.TypeRef, len(f.Fields))
+		c.logf("\t# CONV: field [%s] type=%s flen=%d at %s\n",
+			strings.Join(f.Names, ","), f.TypeRef, len(f.Fields), f.Pos)
 		if len(f.Names) == 0 {
 			// embedded field
 			if len(f.Fields) == 0 {
@@ -84,6 +101,8 @@ func (c *Converter) DocItemsFromFields(res Resolver, file *ast.FileSpec, prefix
 	return items
 }
 
+// DocItemsFromField converts field to doc items, one item per env name,
+// nested struct fields are converted to children.
 func (c *Converter) DocItemsFromField(resolver Resolver, file *ast.FileSpec, prefix string, f *ast.FieldSpec) []*types.EnvDocItem {
 	dec := NewFieldDecoder(c.target, FieldDecoderOpts{
 		EnvPrefix:       prefix,
@@ -101,24 +120,24 @@ func (c *Converter) DocItemsFromField(resolver Resolver, file *ast.FileSpec, pre
 	switch f.TypeRef.Kind {
 	case ast.FieldTypeStruct:
 		children = c.DocItemsFromFields(resolver, file, prefix, f.Fields)
-		debug.Logf("\t# CONV: struct %q (%d childrens)\n", f.TypeRef.String(), len(children))
+		c.logf("\t# CONV: struct %q (%d childrens)\n", f.TypeRef.String(), len(children))
 	case ast.FieldTypeSelector, ast.FieldTypeIdent, ast.FieldTypeArray, ast.FieldTypePtr:
 		if f.TypeRef.IsBuiltIn() {
 			break
 		}
 		tpe := resolver.Resolve(file, &f.TypeRef)
-		debug.Logf("\t# CONV: resolve %q -> %v\n", f.TypeRef.String(), tpe)
+		c.logf("\t# CONV: resolve %q -> %v\n", f.TypeRef.String(), tpe)
 		if tpe == nil {
 			if newPrefix != "" {
 				// Target type is env-prefixed, it means it's a reference
 				// to another struct type. We can't process it here, because
 				// we can't resolve the target type and its fields.
-				fmt.Fprintf(os.Stderr, "WARNING: failed to resolve type %q\n", f.TypeRef.String())
+				c.warnf(f.Pos, "failed to resolve type %q", f.TypeRef.String())
 			}
 			break
 		}
 		children = c.DocItemsFromFields(resolver, file, prefix, tpe.Fields)
-		debug.Logf("\t# CONV: selector %q (%d childrens)\n", f.TypeRef.String(), len(children))
+		c.logf("\t# CONV: selector %q (%d childrens)\n", f.TypeRef.String(), len(children))
 	}
 
 	res := make([]*types.EnvDocItem, len(info.Names), len(info.Names)+1)
@@ -130,14 +149,22 @@ func (c *Converter) DocItemsFromField(resolver Resolver, file *ast.FileSpec, pre
 		Default:   info.Default,
 		Separator: info.Separator,
 	}
+	var buildConstraint string
+	if c.opts.BuildConstraints {
+		buildConstraint = file.BuildConstraint
+	}
 	for i, name := range info.Names {
 		res[i] = &types.EnvDocItem{
-			Name:     name,
-			Doc:      f.Doc,
-			Opts:     opts,
-			Children: children,
+			Name:            name,
+			Doc:             f.Doc,
+			Type:            fieldTypeName(f.TypeRef, file.Pkg),
+			Opts:            opts,
+			Children:        children,
+			BuildConstraint: buildConstraint,
+			File:            f.Pos.File,
+			Line:            f.Pos.Line,
 		}
-		debug.Logf("\t# CONV: docItem %q (%d childrens)\n", name, len(children))
+		c.logf("\t# CONV: docItem %q (%d childrens)\n", name, len(children))
 	}
 
 	if len(info.Names) == 0 && len(children) > 0 {
@@ -145,3 +172,42 @@ func (c *Converter) DocItemsFromField(resolver Resolver, file *ast.FileSpec, pre
 	}
 	return res
 }
+
+// fieldTypeName returns Go type name of field type reference,
+// types of pkg package are not qualified. It returns empty string
+// for anonymous structs.
+func fieldTypeName(ref ast.FieldTypeRef, pkg string) string {
+	name := ref.Name
+	if ref.Pkg != "" && ref.Pkg != pkg && !ref.IsBuiltIn() {
+		name = ref.Pkg + "." + name
+	}
+	switch ref.Kind {
+	case ast.FieldTypePtr:
+		return "*" + name
+	case ast.FieldTypeArray:
+		return "[]" + name
+	case ast.FieldTypeMap:
+		return "map[" + ref.Key + "]" + name
+	case ast.FieldTypeStruct:
+		return ""
+	}
+	return name
+}
+
+// warnf prints a warning prefixed with source position if it's known.
+func (c *Converter) warnf(pos ast.Position, format string, args ...any) {
+	if c.opts.Warnings == nil {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if pos.File != "" {
+		msg = pos.String() + ": " + msg
+	}
+	fmt.Fprintln(c.opts.Warnings, "WARNING: "+msg)
+}
+
+func (c *Converter) logf(format string, args ...any) {
+	if c.opts.Logger != nil {
+		c.opts.Logger.Logf(format, args...)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package envdoc
 
 import (
 	"fmt"
@@ -321,6 +321,80 @@ func TestConverterScopes(t *testing.T) {
 	}
 }
 
+func TestConverterBuildConstraints(t *testing.T) {
+	files := []*ast.FileSpec{
+		{
+			Name:            "config_linux.go",
+			Pkg:             "main",
+			Export:          true,
+			BuildConstraint: "linux",
+			Types: []*ast.TypeSpec{
+				{
+					Name:   "Config",
+					Export: true,
+					Fields: []*ast.FieldSpec{
+						{
+							Names:   []string{"Cgroup"},
+							TypeRef: ast.FieldTypeRef{Name: "string", Kind: ast.FieldTypeIdent},
+							Tag:     `env:"CGROUP"`,
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, enabled := range []bool{false, true} {
+		opts := opts
+		opts.BuildConstraints = enabled
+		scopes := NewConverter(types.TargetTypeCaarlos0, opts).ScopesFromFiles(resolver.NewTypeResolver(), files)
+		if len(scopes) != 1 || len(scopes[0].Vars) != 1 {
+			t.Fatalf("unexpected scopes: %+v", scopes)
+		}
+		expect := ""
+		if enabled {
+			expect = "linux"
+		}
+		if res := scopes[0].Vars[0].BuildConstraint; res != expect {
+			t.Errorf("BuildConstraints=%t: expected build constraint %q, got %q", enabled, expect, res)
+		}
+	}
+}
+
+func TestConverterPositions(t *testing.T) {
+	files := []*ast.FileSpec{
+		{
+			Name:   "config.go",
+			Pkg:    "main",
+			Export: true,
+			Types: []*ast.TypeSpec{
+				{
+					Name:   "Config",
+					Export: true,
+					Pos:    ast.Position{File: "config.go", Line: 3},
+					Fields: []*ast.FieldSpec{
+						{
+							Names:   []string{"Port"},
+							TypeRef: ast.FieldTypeRef{Name: "int", Kind: ast.FieldTypeIdent},
+							Tag:     `env:"PORT"`,
+							Pos:     ast.Position{File: "config.go", Line: 5},
+						},
+					},
+				},
+			},
+		},
+	}
+	scopes := NewConverter(types.TargetTypeCaarlos0, opts).ScopesFromFiles(resolver.NewTypeResolver(), files)
+	if len(scopes) != 1 || len(scopes[0].Vars) != 1 {
+		t.Fatalf("unexpected scopes: %+v", scopes)
+	}
+	if scope := scopes[0]; scope.File != "config.go" || scope.Line != 3 {
+		t.Errorf("unexpected scope position %s:%d", scope.File, scope.Line)
+	}
+	if item := scopes[0].Vars[0]; item.File != "config.go" || item.Line != 5 {
+		t.Errorf("unexpected item position %s:%d", item.File, item.Line)
+	}
+}
+
 func TestConverterFailedToResolve(t *testing.T) {
 	field := &ast.FieldSpec{
 		Names: []string{"BarField"},
@@ -374,3 +448,23 @@ func checkDocItem(t *testing.T, scope string, expect, actual *types.EnvDocItem)
 		checkDocItem(t, fmt.Sprintf("%s/%d", scope, i), child, actual.Children[i])
 	}
 }
+
+func TestFieldTypeName(t *testing.T) {
+	for _, tc := range []struct {
+		ref    ast.FieldTypeRef
+		expect string
+	}{
+		{ast.FieldTypeRef{Name: "int", Pkg: "main", Kind: ast.FieldTypeIdent}, "int"},
+		{ast.FieldTypeRef{Name: "Level", Pkg: "main", Kind: ast.FieldTypeIdent}, "Level"},
+		{ast.FieldTypeRef{Name: "Duration", Pkg: "time", Kind: ast.FieldTypeSelector}, "time.Duration"},
+		{ast.FieldTypeRef{Name: "Duration", Pkg: "time", Kind: ast.FieldTypePtr}, "*time.Duration"},
+		{ast.FieldTypeRef{Name: "string", Pkg: "main", Kind: ast.FieldTypeArray}, "[]string"},
+		{ast.FieldTypeRef{Name: "int", Pkg: "main", Kind: ast.FieldTypeMap, Key: "string"}, "map[string]int"},
+		{ast.FieldTypeRef{Name: "bool", Pkg: "main", Kind: ast.FieldTypeMap, Key: "int"}, "map[int]bool"},
+		{ast.FieldTypeRef{Kind: ast.FieldTypeStruct}, ""},
+	} {
+		if actual := fieldTypeName(tc.ref, "main"); actual != tc.expect {
+			t.Errorf("%v: expected %q, got %q", tc.ref, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package envdoc
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/g4s8/envdoc/ast"
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/types"
+)
+
+// Targets of Describe.
+const (
+	TargetCaarlos0 = types.TargetTypeCaarlos0
+	TargetCleanenv = types.TargetTypeCleanenv
+)
+
+// WithDocs sets JSON documentation generated by envdoc for Describe,
+// e.g. a file embedded with go:embed. Reflection can't read doc comments,
+// so docs of variables and scopes are taken from it by names.
+func WithDocs(data []byte) Option {
+	return func(o *options) {
+		o.docs = data
+	}
+}
+
+// Describe returns documentation scope of struct v, or of struct pointed by v.
+// It walks struct fields by reflection and reads tags of target env library
+// the same way as Load reads them from sources, so it can document configs
+// assembled from types of other modules.
+func Describe(v any, target types.TargetType, opts ...Option) ([]*types.EnvScope, error) {
+	o := newOptions(opts)
+	if target != types.TargetTypeCaarlos0 && target != types.TargetTypeCleanenv {
+		return nil, fmt.Errorf("unknown target: %d", target)
+	}
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct or pointer to struct, got %T", v)
+	}
+
+	r := newReflectResolver(t)
+	spec := r.typeSpec(t)
+	file := &ast.FileSpec{Pkg: pkgName(t), Export: true, Types: []*ast.TypeSpec{spec}}
+	conv := NewConverter(target, ConverterOpts{
+		EnvPrefix:       o.envPrefix,
+		TagName:         o.tagName,
+		TagDefault:      o.tagDefault,
+		RequiredIfNoDef: o.requiredIfNoDef,
+		UseFieldNames:   o.fieldNames,
+		Warnings:        o.warnings,
+		Logger:          o.logger,
+	})
+	scopes := conv.ScopesFromFiles(r, []*ast.FileSpec{file})
+	if o.docs != nil {
+		docs, err := render.ReadJSON(bytes.NewReader(o.docs))
+		if err != nil {
+			return nil, fmt.Errorf("read docs: %w", err)
+		}
+		attachDocs(scopes, docs)
+	}
+	return scopes, nil
+}
+
+// reflectResolver builds type specs of struct types by reflection
+// and resolves field type references to them. Types are keyed by package
+// path, references use package names, which are made unique like import
+// aliases if packages of different paths have the same name.
+type reflectResolver struct {
+	// root is a package path of described type
+	root  string
+	types map[string]*ast.TypeSpec
+	// paths are package paths by package names of references
+	paths map[string]string
+	// names are package names of references by package paths
+	names map[string]string
+}
+
+func newReflectResolver(t reflect.Type) *reflectResolver {
+	r := &reflectResolver{
+		root:  t.PkgPath(),
+		types: make(map[string]*ast.TypeSpec),
+		paths: make(map[string]string),
+		names: make(map[string]string),
+	}
+	// package name of described type is reserved to not render
+	// types of other packages with the same name as local ones
+	if name := pkgName(t); name != "" {
+		r.paths[name] = r.root
+	}
+	return r
+}
+
+func (r *reflectResolver) Resolve(_ *ast.FileSpec, ref *ast.FieldTypeRef) *ast.TypeSpec {
+	path := r.root
+	if ref.Pkg != "" {
+		var ok bool
+		if path, ok = r.paths[ref.Pkg]; !ok {
+			return nil
+		}
+	}
+	return r.types[path+"."+ref.Name]
+}
+
+func (r *reflectResolver) typeSpec(t reflect.Type) *ast.TypeSpec {
+	spec := &ast.TypeSpec{Name: t.Name(), Export: true}
+	if t.Name() != "" {
+		// register before fields to stop on recursive types
+		r.types[r.typeKey(t)] = spec
+	}
+	spec.Fields = r.fieldSpecs(t)
+	return spec
+}
+
+func (r *reflectResolver) fieldSpecs(t reflect.Type) []*ast.FieldSpec {
+	var fields []*ast.FieldSpec
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		f := &ast.FieldSpec{Tag: string(sf.Tag), TypeRef: r.typeRef(sf.Type)}
+		if !sf.Anonymous {
+			f.Names = []string{sf.Name}
+		}
+		if f.TypeRef.Kind == ast.FieldTypeStruct {
+			f.Fields = r.fieldSpecs(sf.Type)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// typeRef returns type reference of field type, named struct types
+// are registered for resolving, unless they are parsed from text by env
+// libraries, e.g. time.Time.
+func (r *reflectResolver) typeRef(t reflect.Type) ast.FieldTypeRef {
+	var kind ast.FieldTypeRefKind
+	var key string
+	switch t.Kind() {
+	case reflect.Ptr:
+		kind, t = ast.FieldTypePtr, t.Elem()
+	case reflect.Slice, reflect.Array:
+		kind, t = ast.FieldTypeArray, t.Elem()
+	case reflect.Map:
+		kind, key, t = ast.FieldTypeMap, r.typeName(t.Key()), t.Elem()
+	case reflect.Struct:
+		if t.Name() == "" {
+			return ast.FieldTypeRef{Kind: ast.FieldTypeStruct}
+		}
+		kind = ast.FieldTypeIdent
+	default:
+		kind = ast.FieldTypeIdent
+	}
+	ref := ast.FieldTypeRef{Name: t.Name(), Kind: kind, Key: key}
+	if t.PkgPath() != r.root {
+		ref.Pkg = r.pkgAlias(t)
+		if kind == ast.FieldTypeIdent {
+			ref.Kind = ast.FieldTypeSelector
+		}
+	}
+	if t.Kind() == reflect.Struct && t.Name() != "" && !isTextType(t) {
+		if _, ok := r.types[r.typeKey(t)]; !ok {
+			r.typeSpec(t)
+		}
+	}
+	return ref
+}
+
+// typeName returns Go type name of t as it's written in the root package.
+func (r *reflectResolver) typeName(t reflect.Type) string {
+	switch {
+	case t.Name() == "":
+		return t.String()
+	case t.PkgPath() == "" || t.PkgPath() == r.root:
+		return t.Name()
+	}
+	return r.pkgAlias(t) + "." + t.Name()
+}
+
+func (r *reflectResolver) typeKey(t reflect.Type) string {
+	return t.PkgPath() + "." + t.Name()
+}
+
+// pkgAlias returns package name of type for references, it's suffixed
+// with a number if another package with the same name is referenced.
+func (r *reflectResolver) pkgAlias(t reflect.Type) string {
+	path := t.PkgPath()
+	if path == "" {
+		return ""
+	}
+	if name, ok := r.names[path]; ok {
+		return name
+	}
+	base := pkgName(t)
+	name := base
+	for i := 2; r.paths[name] != ""; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	r.paths[name] = path
+	r.names[path] = name
+	return name
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	urlType             = reflect.TypeFor[url.URL]()
+)
+
+// isTextType checks if struct type is parsed from text value
+// instead of nested variables.
+func isTextType(t reflect.Type) bool {
+	return t == urlType || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// pkgName returns package name of named type, or empty string
+// for builtin types.
+func pkgName(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return ""
+	}
+	name, _, _ := strings.Cut(t.String(), ".")
+	return name
+}
+
+// attachDocs sets docs and source positions of scopes and variables
+// from generated documentation, variables are matched by name, unnamed
+// groups of nested variables by the first named variable.
+func attachDocs(scopes, docs []*types.EnvScope) {
+	scopeDocs := make(map[string]*types.EnvScope, len(docs))
+	itemDocs := make(map[string]*types.EnvDocItem)
+	groupDocs := make(map[string]*types.EnvDocItem)
+	var index func(items []*types.EnvDocItem)
+	index = func(items []*types.EnvDocItem) {
+		for _, item := range items {
+			if item.Name != "" {
+				itemDocs[item.Name] = item
+			} else if name := firstVarName(item.Children); name != "" {
+				groupDocs[name] = item
+			}
+			index(item.Children)
+		}
+	}
+	for _, s := range docs {
+		scopeDocs[s.Name] = s
+		index(s.Vars)
+	}
+
+	var attach func(items []*types.EnvDocItem)
+	attach = func(items []*types.EnvDocItem) {
+		for _, item := range items {
+			var doc *types.EnvDocItem
+			if item.Name != "" {
+				doc = itemDocs[item.Name]
+			} else {
+				doc = groupDocs[firstVarName(item.Children)]
+			}
+			if doc != nil {
+				item.Doc, item.File, item.Line = doc.Doc, doc.File, doc.Line
+			}
+			attach(item.Children)
+		}
+	}
+	for _, s := range scopes {
+		if doc, ok := scopeDocs[s.Name]; ok {
+			s.Doc, s.File, s.Line = doc.Doc, doc.File, doc.Line
+		}
+		attach(s.Vars)
+	}
+}
+
+func firstVarName(items []*types.EnvDocItem) string {
+	for _, item := range items {
+		if item.Name != "" {
+			return item.Name
+		}
+		if name := firstVarName(item.Children); name != "" {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package envdoc
+
+import (
+	"math/rand"
+	randv2 "math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+type describeBase struct {
+	Version string `env:"VERSION" envDefault:"v1"`
+}
+
+type describeDB struct {
+	Host string `env:"HOST,required"`
+	Port int    `env:"PORT" envDefault:"5432"`
+}
+
+type describeConfig struct {
+	describeBase
+	Hosts    []string      `env:"HOSTS" envSeparator:";"`
+	Timeout  time.Duration `env:"TIMEOUT" envDefault:"5s"`
+	Started  time.Time     `env:"STARTED"`
+	Flags    map[int]bool  `env:"FLAGS"`
+	Password string        `env:"PASSWORD,file,notEmpty"`
+	DB       describeDB    `envPrefix:"DB_"`
+	Replica  *describeDB   `envPrefix:"REPLICA_"`
+	Cache    struct {
+		Size int `env:"CACHE_SIZE"`
+	}
+	ignored string //nolint:unused
+}
+
+type cleanenvConfig struct {
+	Host string `env:"HOST" env-required:"true"`
+	Port int    `env:"PORT" env-default:"8080"`
+}
+
+func describeItems(items []*types.EnvDocItem) string {
+	var sb strings.Builder
+	var walk func(prefix string, items []*types.EnvDocItem)
+	walk = func(prefix string, items []*types.EnvDocItem) {
+		for _, item := range items {
+			sb.WriteString(prefix + item.Name + " " + item.Type)
+			if item.Opts.Required {
+				sb.WriteString(" required")
+			}
+			if item.Opts.NonEmpty {
+				sb.WriteString(" non-empty")
+			}
+			if item.Opts.FromFile {
+				sb.WriteString(" file")
+			}
+			if item.Opts.Default != "" {
+				sb.WriteString(" default=" + item.Opts.Default)
+			}
+			if item.Opts.Separator != "" {
+				sb.WriteString(" sep=" + item.Opts.Separator)
+			}
+			if item.Doc != "" {
+				sb.WriteString(" doc=" + item.Doc)
+			}
+			sb.WriteString("\n")
+			walk(prefix+"  ", item.Children)
+		}
+	}
+	walk("", items)
+	return sb.String()
+}
+
+func TestDescribe(t *testing.T) {
+	scopes, err := Describe(&describeConfig{}, TargetCaarlos0, WithEnvPrefix("APP_"))
+	testutils.AssertFatal(t, err == nil, "describe: %v", err)
+	testutils.AssertFatal(t, len(scopes) == 1, "expected 1 scope, got %d", len(scopes))
+	testutils.AssertError(t, scopes[0].Name == "describeConfig", "unexpected scope name: %q", scopes[0].Name)
+	expect := "APP_VERSION string default=v1\n" +
+		"APP_HOSTS []string sep=;\n" +
+		"APP_TIMEOUT time.Duration default=5s\n" +
+		"APP_STARTED time.Time\n" +
+		"APP_FLAGS map[int]bool\n" +
+		"APP_PASSWORD string required non-empty file\n" +
+		" describeDB\n" +
+		"  APP_DB_HOST string required\n" +
+		"  APP_DB_PORT int default=5432\n" +
+		" *describeDB\n" +
+		"  APP_REPLICA_HOST string required\n" +
+		"  APP_REPLICA_PORT int default=5432\n" +
+		" \n" +
+		"  APP_CACHE_SIZE int\n"
+	actual := describeItems(scopes[0].Vars)
+	testutils.AssertError(t, actual == expect, "unexpected items:\n%s", actual)
+
+	scopes, err = Describe(cleanenvConfig{}, TargetCleanenv)
+	testutils.AssertFatal(t, err == nil, "describe: %v", err)
+	actual = describeItems(scopes[0].Vars)
+	testutils.AssertError(t, actual == "HOST string required\nPORT int default=8080\n",
+		"unexpected cleanenv items:\n%s", actual)
+
+	_, err = Describe(42, TargetCaarlos0)
+	testutils.AssertError(t, err != nil, "expected error for non-struct value")
+	_, err = Describe(nil, TargetCaarlos0)
+	testutils.AssertError(t, err != nil, "expected error for nil value")
+}
+
+func TestDescribeDocs(t *testing.T) {
+	docs := []*types.EnvScope{
+		{
+			Name: "describeDB",
+			Doc:  "Database config.",
+			Vars: []*types.EnvDocItem{
+				{Name: "HOST", Doc: "Database host.", File: "config.go", Line: 5},
+				{Name: "PORT", Doc: "Database port."},
+			},
+		},
+	}
+	var data strings.Builder
+	err := Render(docs, types.OutFormatJSON, &data)
+	testutils.AssertFatal(t, err == nil, "render docs: %v", err)
+
+	scopes, err := Describe(&describeDB{}, TargetCaarlos0, WithDocs([]byte(data.String())))
+	testutils.AssertFatal(t, err == nil, "describe: %v", err)
+	scope := scopes[0]
+	testutils.AssertError(t, scope.Doc == "Database config.", "unexpected scope doc: %q", scope.Doc)
+	actual := describeItems(scope.Vars)
+	testutils.AssertError(t, actual == "HOST string required doc=Database host.\n"+
+		"PORT int default=5432 doc=Database port.\n", "unexpected items:\n%s", actual)
+	testutils.AssertError(t, scope.Vars[0].File == "config.go" && scope.Vars[0].Line == 5,
+		"unexpected position: %s:%d", scope.Vars[0].File, scope.Vars[0].Line)
+
+	_, err = Describe(&describeDB{}, TargetCaarlos0, WithDocs([]byte("not json")))
+	testutils.AssertError(t, err != nil, "expected error for invalid docs")
+}
+
+func TestDescribeSamePackageNames(t *testing.T) {
+	type config struct {
+		V1 rand.Rand
+		V2 randv2.Rand
+	}
+	typ := reflect.TypeFor[config]()
+	r := newReflectResolver(typ)
+	spec := r.typeSpec(typ)
+	v1, v2 := spec.Fields[0].TypeRef, spec.Fields[1].TypeRef
+	testutils.AssertError(t, v1.Pkg == "rand" && v2.Pkg == "rand2",
+		"unexpected package names: %q, %q", v1.Pkg, v2.Pkg)
+	t1, t2 := r.Resolve(nil, &v1), r.Resolve(nil, &v2)
+	testutils.AssertFatal(t, t1 != nil && t2 != nil, "failed to resolve types: %v, %v", t1, t2)
+	testutils.AssertError(t, t1 != t2, "types of different packages resolved to the same spec")
+}
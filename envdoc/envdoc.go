@@ -0,0 +1,316 @@
+// Package envdoc generates documentation of environment variables
+// from Go config structures programmatically, it works the same way
+// as envdoc command, e.g.:
+//
+//	scopes, err := envdoc.Load(envdoc.WithDir("./config"), envdoc.WithTypeGlob("Config"))
+//	if err != nil {
+//		return err
+//	}
+//	err = envdoc.Render(scopes, types.OutFormatMarkdown, w)
+//
+// Functions of the package don't write to standard output or error,
+// warnings and debug messages are written only to outputs set by options.
+package envdoc
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+
+	"github.com/g4s8/envdoc/ast"
+	"github.com/g4s8/envdoc/debug"
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/resolver"
+	"github.com/g4s8/envdoc/types"
+)
+
+type options struct {
+	dir               string
+	fileGlob          string
+	typeGlob          string
+	target            types.TargetType
+	envPrefix         string
+	tagName           string
+	tagDefault        string
+	requiredIfNoDef   bool
+	fieldNames        bool
+	concurrency       int
+	exclude           []string
+	skipNestedModules bool
+	buildTags         []string
+	goos, goarch      string
+	allTags           bool
+	diskCache         *ast.DiskCache
+	docs              []byte
+
+	noStyles       bool
+	composeService string
+	manSectionOnly bool
+	goPackage      string
+	templateFile   string
+	sourceLink     func(file string, line int) string
+
+	warnings io.Writer
+	logger   debug.Logger
+}
+
+// Option configures loading and rendering of documentation,
+// options mirror flags of envdoc command.
+type Option func(*options)
+
+// WithDir sets directory to search for files, it's current directory by default.
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithFileGlob filters documented files by name glob, like -files flag.
+func WithFileGlob(glob string) Option {
+	return func(o *options) {
+		o.fileGlob = glob
+	}
+}
+
+// WithTypeGlob filters documented types by name glob, like -types flag,
+// it's "*" by default.
+func WithTypeGlob(glob string) Option {
+	return func(o *options) {
+		o.typeGlob = glob
+	}
+}
+
+// WithTarget sets env library of field tags, like -target flag,
+// it's caarlos0/env by default.
+func WithTarget(target types.TargetType) Option {
+	return func(o *options) {
+		o.target = target
+	}
+}
+
+// WithEnvPrefix sets prefix of all variables, like -env-prefix flag.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithTagName sets env name tag, like -tag-name flag, it's "env" by default.
+func WithTagName(name string) Option {
+	return func(o *options) {
+		o.tagName = name
+	}
+}
+
+// WithTagDefault sets default value tag, like -tag-default flag,
+// it's "envDefault" by default.
+func WithTagDefault(name string) Option {
+	return func(o *options) {
+		o.tagDefault = name
+	}
+}
+
+// WithRequiredIfNoDef marks variables without default values as required,
+// like -required-if-no-def flag.
+func WithRequiredIfNoDef(required bool) Option {
+	return func(o *options) {
+		o.requiredIfNoDef = required
+	}
+}
+
+// WithFieldNames uses field names of fields without env tag,
+// like -field-names flag.
+func WithFieldNames(fieldNames bool) Option {
+	return func(o *options) {
+		o.fieldNames = fieldNames
+	}
+}
+
+// WithConcurrency sets the maximum number of directories parsed concurrently,
+// like -concurrency flag, it's GOMAXPROCS by default.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithExclude skips subdirectories matching glob patterns, like -exclude flag.
+func WithExclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// WithSkipNestedModules skips subdirectories with go.mod file,
+// like -skip-nested-modules flag.
+func WithSkipNestedModules(skip bool) Option {
+	return func(o *options) {
+		o.skipNestedModules = skip
+	}
+}
+
+// WithBuildTags sets build tags to match files, like -tags flag.
+func WithBuildTags(tags ...string) Option {
+	return func(o *options) {
+		o.buildTags = append(o.buildTags, tags...)
+	}
+}
+
+// WithPlatform sets GOOS and GOARCH to match files, like -goos and -goarch flags,
+// empty values keep current GOOS or GOARCH.
+func WithPlatform(goos, goarch string) Option {
+	return func(o *options) {
+		o.goos, o.goarch = goos, goarch
+	}
+}
+
+// WithAllTags parses files of all build constraints and adds build
+// constraint notes to variables, like -all-tags flag.
+func WithAllTags(all bool) Option {
+	return func(o *options) {
+		o.allTags = all
+	}
+}
+
+// WithDiskCache enables on-disk cache of parsed files, it's disabled by default.
+func WithDiskCache(cache *ast.DiskCache) Option {
+	return func(o *options) {
+		o.diskCache = cache
+	}
+}
+
+// WithNoStyles disables styles of HTML format, like -no-styles flag.
+func WithNoStyles(noStyles bool) Option {
+	return func(o *options) {
+		o.noStyles = noStyles
+	}
+}
+
+// WithComposeService sets service name of compose format, like -compose-service flag.
+func WithComposeService(service string) Option {
+	return func(o *options) {
+		o.composeService = service
+	}
+}
+
+// WithManSectionOnly renders only ENVIRONMENT section of man format,
+// like -man-section-only flag.
+func WithManSectionOnly(sectionOnly bool) Option {
+	return func(o *options) {
+		o.manSectionOnly = sectionOnly
+	}
+}
+
+// WithGoPackage sets package name of go format, like -go-package flag.
+func WithGoPackage(name string) Option {
+	return func(o *options) {
+		o.goPackage = name
+	}
+}
+
+// WithTemplateFile sets custom template file, like -template flag.
+func WithTemplateFile(file string) Option {
+	return func(o *options) {
+		o.templateFile = file
+	}
+}
+
+// WithSourceLink sets a function which returns a link to variable
+// declaration, like -source-link-template flag.
+func WithSourceLink(link func(file string, line int) string) Option {
+	return func(o *options) {
+		o.sourceLink = link
+	}
+}
+
+// WithWarnings sets an output for warnings, e.g. unresolved types
+// or skipped subdirectories, warnings are discarded by default.
+func WithWarnings(w io.Writer) Option {
+	return func(o *options) {
+		o.warnings = w
+	}
+}
+
+// WithDebug writes debug messages of parsing, type resolution and conversion to w.
+func WithDebug(w io.Writer) Option {
+	return func(o *options) {
+		o.logger = debug.NewLogger(w)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		dir:            ".",
+		typeGlob:       "*",
+		target:         types.TargetTypeCaarlos0,
+		tagName:        "env",
+		tagDefault:     "envDefault",
+		composeService: render.DefaultComposeService,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o options) buildContext() *build.Context {
+	if o.allTags {
+		return nil
+	}
+	ctx := build.Default
+	if o.goos != "" {
+		ctx.GOOS = o.goos
+	}
+	if o.goarch != "" {
+		ctx.GOARCH = o.goarch
+	}
+	ctx.BuildTags = append(ctx.BuildTags, o.buildTags...)
+	return &ctx
+}
+
+// Load parses Go sources and returns documentation scopes of config types.
+func Load(opts ...Option) ([]*types.EnvScope, error) {
+	o := newOptions(opts)
+	if o.target != types.TargetTypeCaarlos0 && o.target != types.TargetTypeCleanenv {
+		return nil, fmt.Errorf("unknown target: %d", o.target)
+	}
+	parserOpts := []ast.ParserConfigOption{
+		ast.WithConcurrency(o.concurrency),
+		ast.WithExclude(o.exclude...),
+		ast.WithSkipNestedModules(o.skipNestedModules),
+		ast.WithBuildContext(o.buildContext()),
+		ast.WithWarnings(o.warnings),
+		ast.WithDebug(o.logger != nil),
+		ast.WithLogger(o.logger),
+	}
+	if o.diskCache != nil {
+		parserOpts = append(parserOpts, ast.WithDiskCache(o.diskCache))
+	}
+	files, err := ast.NewParser(o.fileGlob, o.typeGlob, parserOpts...).Parse(o.dir)
+	if err != nil {
+		return nil, fmt.Errorf("parse dir: %w", err)
+	}
+	conv := NewConverter(o.target, ConverterOpts{
+		EnvPrefix:        o.envPrefix,
+		TagName:          o.tagName,
+		TagDefault:       o.tagDefault,
+		RequiredIfNoDef:  o.requiredIfNoDef,
+		UseFieldNames:    o.fieldNames,
+		BuildConstraints: o.allTags,
+		Warnings:         o.warnings,
+		Logger:           o.logger,
+	})
+	return conv.ScopesFromFiles(resolver.ResolveAllTypes(files, resolver.WithLogger(o.logger)), files), nil
+}
+
+// Render writes documentation of scopes in format to w.
+func Render(scopes []*types.EnvScope, format types.OutFormat, w io.Writer, opts ...Option) error {
+	o := newOptions(opts)
+	r := render.NewRenderer(format, o.noStyles,
+		render.WithComposeService(o.composeService),
+		render.WithSectionOnly(o.manSectionOnly),
+		render.WithTemplateFile(o.templateFile),
+		render.WithGoPackage(o.goPackage),
+		render.WithSourceLink(o.sourceLink))
+	return r.Render(scopes, w)
+}
@@ -0,0 +1,118 @@
+package envdoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func writeTestSource(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeTestSource(t, `package config
+
+// Config is an app config.
+type Config struct {
+	// Port to listen on.
+	Port int `+"`env:\"PORT,required\"`"+`
+	// Debug mode.
+	Debug bool `+"`env:\"DEBUG\" envDefault:\"false\"`"+`
+	// DB config.
+	DB DB `+"`envPrefix:\"DB_\"`"+`
+}
+
+type DB struct {
+	// Host of database.
+	Host string `+"`env:\"HOST\"`"+`
+}
+
+type internal struct {
+	Name string `+"`env:\"NAME\"`"+`
+}
+`)
+
+	scopes, err := Load(WithDir(dir), WithTypeGlob("Config"), WithEnvPrefix("APP_"))
+	testutils.AssertFatal(t, err == nil, "load: %v", err)
+	testutils.AssertFatal(t, len(scopes) == 1, "expected 1 scope, got %d", len(scopes))
+	scope := scopes[0]
+	testutils.AssertError(t, scope.Name == "Config" && scope.Doc == "Config is an app config.",
+		"unexpected scope: %+v", scope)
+	testutils.AssertFatal(t, len(scope.Vars) == 3, "expected 3 vars, got %d", len(scope.Vars))
+	port := scope.Vars[0]
+	testutils.AssertError(t, port.Name == "APP_PORT" && port.Type == "int" && port.Opts.Required,
+		"unexpected port var: %+v", port)
+	testutils.AssertError(t, port.Line == 6 && filepath.Base(port.File) == "config.go",
+		"unexpected port position: %s:%d", port.File, port.Line)
+	testutils.AssertError(t, scope.Vars[1].Opts.Default == "false", "unexpected debug var: %+v", scope.Vars[1])
+	db := scope.Vars[2]
+	testutils.AssertFatal(t, len(db.Children) == 1, "expected 1 child of DB, got %d", len(db.Children))
+	testutils.AssertError(t, db.Children[0].Name == "APP_DB_HOST", "unexpected child: %+v", db.Children[0])
+
+	_, err = Load(WithDir(filepath.Join(dir, "missing")))
+	testutils.AssertError(t, err != nil, "expected error for missing dir")
+	_, err = Load(WithDir(dir), WithTarget(types.TargetType(42)))
+	testutils.AssertError(t, err != nil, "expected error for unknown target")
+}
+
+func TestLoadWarnings(t *testing.T) {
+	dir := writeTestSource(t, `package config
+
+type Config struct {
+	Remote remote.Config `+"`envPrefix:\"REMOTE_\"`"+`
+}
+`)
+	var warnings, debug strings.Builder
+	_, err := Load(WithDir(dir), WithWarnings(&warnings), WithDebug(&debug))
+	testutils.AssertFatal(t, err == nil, "load: %v", err)
+	testutils.AssertError(t, strings.Contains(warnings.String(), `config.go:4: failed to resolve type "remote.Config"`),
+		"unexpected warnings: %q", warnings.String())
+	// parser and resolver log to the same logger
+	for _, expect := range []string{`# CONV: found scope "Config"`, "# AST(file): Type Config", "# RES: ref=", "Parsed types:"} {
+		testutils.AssertError(t, strings.Contains(debug.String(), expect),
+			"expected %q in debug output: %q", expect, debug.String())
+	}
+
+	// warnings are discarded by default
+	_, err = Load(WithDir(dir))
+	testutils.AssertError(t, err == nil, "load: %v", err)
+}
+
+func TestRender(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{Name: "PORT", Doc: "Port to listen on.", Opts: types.EnvVarOptions{Required: true}},
+			},
+		},
+	}
+	var out strings.Builder
+	err := Render(scopes, types.OutFormatEnv, &out)
+	testutils.AssertFatal(t, err == nil, "render: %v", err)
+	testutils.AssertError(t, strings.Contains(out.String(), "# Port to listen on.\n# (required)\nPORT=\"<FIXME>\""),
+		"unexpected output:\n%s", out.String())
+
+	out.Reset()
+	scopes[0].Vars[0].File, scopes[0].Vars[0].Line = "config.go", 5
+	err = Render(scopes, types.OutFormatMarkdown, &out, WithSourceLink(func(file string, line int) string {
+		return fmt.Sprintf("https://example.com/%s#L%d", file, line)
+	}))
+	testutils.AssertFatal(t, err == nil, "render: %v", err)
+	testutils.AssertError(t, strings.Contains(out.String(), "(https://example.com/config.go#L5)"),
+		"unexpected output:\n%s", out.String())
+
+	err = Render(scopes, types.OutFormat("unknown"), &out)
+	testutils.AssertError(t, err != nil, "expected error for unknown format")
+}
@@ -1,4 +1,4 @@
-package main
+package envdoc
 
 import (
 	"github.com/g4s8/envdoc/ast"
@@ -7,6 +7,7 @@ import (
 	"github.com/g4s8/envdoc/utils"
 )
 
+// FieldInfo is an env variable info decoded from field tags.
 type FieldInfo struct {
 	Names     []string
 	Required  bool
@@ -17,10 +18,13 @@ type FieldInfo struct {
 	Separator string
 }
 
+// FieldDecoder decodes field tags of target env library, Decode
+// returns env prefix of nested struct fields if field has it.
 type FieldDecoder interface {
 	Decode(f *ast.FieldSpec) (FieldInfo, string)
 }
 
+// FieldDecoderOpts are options of field decoder.
 type FieldDecoderOpts struct {
 	EnvPrefix       string
 	TagName         string
@@ -29,6 +33,7 @@ type FieldDecoderOpts struct {
 	UseFieldNames   bool
 }
 
+// NewFieldDecoder creates field decoder of target, it panics if target is unknown.
 func NewFieldDecoder(target types.TargetType, opts FieldDecoderOpts) FieldDecoder {
 	switch target {
 	case types.TargetTypeCaarlos0:
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	rtdebug "runtime/debug"
+	"strings"
+
+	"github.com/g4s8/envdoc/ast"
+	"github.com/g4s8/envdoc/debug"
+	"github.com/g4s8/envdoc/envdoc"
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/resolver"
+	"github.com/g4s8/envdoc/types"
+)
+
+// generateOutputs parses and converts sources of cfg once, then renders
+// documentation for each output of cfg. It returns output configs
+// with rendered content of each output. Resolved types are shared
+// with other calls of the same sources if resolvers cache is not nil.
+func generateOutputs(cfg Config, resolvers *resolver.Cache, opts ...ast.ParserConfigOption) ([]Config, [][]byte, error) {
+	gen := newGenerator(cfg, nil, opts...)
+	gen.resolvers = resolvers
+
+	outCfgs := cfg.outputConfigs()
+	bufs := make([]bytes.Buffer, len(outCfgs))
+	outputs := make([]Output, len(outCfgs))
+	for i, oc := range outCfgs {
+		outputs[i] = Output{Renderer: newRenderer(oc), Writer: &bufs[i]}
+	}
+	if err := gen.GenerateOutputs(cfg.Dir, outputs); err != nil {
+		return nil, nil, err
+	}
+
+	contents := make([][]byte, len(outCfgs))
+	for i := range bufs {
+		contents[i] = bufs[i].Bytes()
+	}
+	return outCfgs, contents, nil
+}
+
+// loadScopes parses and converts sources of cfg to documentation scopes.
+func loadScopes(cfg Config, opts ...ast.ParserConfigOption) ([]*types.EnvScope, error) {
+	return newGenerator(cfg, nil, opts...).Scopes(cfg.Dir)
+}
+
+// newGenerator creates generator of cfg sources, debug messages
+// are written to stdout if debug is enabled.
+func newGenerator(cfg Config, renderer Renderer, opts ...ast.ParserConfigOption) *Generator {
+	gen := NewGenerator(newParser(cfg, opts...), newConverter(cfg), renderer)
+	gen.log = newLogger(cfg)
+	return gen
+}
+
+// commandScopes loads config of subcommand and parses its sources
+// to documentation scopes using on-disk cache.
+func commandScopes(c *Config, stderr io.Writer) (Config, []*types.EnvScope, error) {
+	cfg, err := c.commandConfig(stderr)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	if cfg.Debug {
+		debug.Config.Enabled = true
+		cfg.fprint(stderr)
+	}
+	scopes, err := loadScopes(cfg, cacheOptions(cfg)...)
+	return cfg, scopes, err
+}
+
+// cacheOptions returns parser options to use on-disk cache if it's enabled.
+func cacheOptions(cfg Config) []ast.ParserConfigOption {
+	if cache := newDiskCache(cfg); cache != nil {
+		return []ast.ParserConfigOption{ast.WithDiskCache(cache)}
+	}
+	return nil
+}
+
+func newParser(cfg Config, opts ...ast.ParserConfigOption) *ast.Parser {
+	opts = append([]ast.ParserConfigOption{
+		ast.WithDebug(cfg.Debug),
+		ast.WithExecConfig(cfg.ExecFile, cfg.ExecLine),
+		ast.WithConcurrency(cfg.Concurrency),
+		ast.WithExclude(cfg.excludes()...),
+		ast.WithSkipNestedModules(cfg.SkipNestedModules),
+		ast.WithBuildContext(cfg.buildContext()),
+		ast.WithLogger(newLogger(cfg)),
+		ast.WithWorkDir(cfg.WorkDir),
+	}, opts...)
+	return ast.NewParser(cfg.FileGlob, cfg.TypeGlob, opts...)
+}
+
+// newLogger returns debug logger writing to stdout,
+// it returns nil if debug is disabled.
+func newLogger(cfg Config) debug.Logger {
+	if !cfg.Debug {
+		return nil
+	}
+	return debug.NewLogger(os.Stdout)
+}
+
+func newConverter(cfg Config) *envdoc.Converter {
+	return envdoc.NewConverter(cfg.Target, envdoc.ConverterOpts{
+		EnvPrefix:       cfg.EnvPrefix,
+		TagName:         cfg.TagName,
+		TagDefault:      cfg.TagDefault,
+		RequiredIfNoDef: cfg.RequiredIfNoDef,
+		UseFieldNames:   cfg.FieldNames,
+
+		BuildConstraints: cfg.AllTags,
+		Warnings:         os.Stderr,
+		Logger:           newLogger(cfg),
+	})
+}
+
+func newRenderer(cfg Config) *render.Renderer {
+	opts := []render.RendererOption{
+		render.WithComposeService(cfg.ComposeService),
+		// HTML page can't be nested into another page in edit mode
+		render.WithSectionOnly(cfg.ManSectionOnly || cfg.Edit && cfg.OutFormat == types.OutFormatHTML),
+		render.WithTemplateFile(cfg.TemplateFile),
+		render.WithGoPackage(cfg.GoPackage),
+	}
+	if cfg.SourceLinkTemplate != "" {
+		opts = append(opts, render.WithSourceLink(sourceLink(cfg.SourceLinkTemplate, cfg.WorkDir)))
+	}
+	return render.NewRenderer(cfg.OutFormat, cfg.NoStyles, opts...)
+}
+
+// newDiskCache returns on-disk cache of parsed files, it returns nil
+// if cache is disabled by -no-cache flag or ENVDOC_CACHE=off environment
+// variable, or if envdoc version is unknown. ENVDOC_CACHE may be set
+// to a cache directory, it's envdoc directory in the user cache dir by default.
+func newDiskCache(cfg Config) *ast.DiskCache {
+	dir := os.Getenv("ENVDOC_CACHE")
+	if cfg.NoCache || dir == "off" {
+		return nil
+	}
+	version := cacheVersion()
+	if version == "" {
+		return nil
+	}
+	if dir == "" {
+		var err error
+		if dir, err = ast.DefaultDiskCacheDir(); err != nil {
+			return nil
+		}
+	}
+	return ast.NewDiskCache(dir, version)
+}
+
+// cacheVersion returns envdoc version for cache entries: module version
+// or VCS revision of development build. It returns empty string if version
+// is unknown or build has uncommitted changes, since cached entries
+// of such builds may be invalid.
+func cacheVersion() string {
+	info, ok := rtdebug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		// pseudo-version of local build with uncommitted changes
+		if strings.HasSuffix(v, "+dirty") {
+			return ""
+		}
+		return v
+	}
+	var revision string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			if s.Value == "true" {
+				return ""
+			}
+		}
+	}
+	if revision == "" {
+		return ""
+	}
+	return "devel-" + revision
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g4s8/envdoc/ast"
+	"github.com/g4s8/envdoc/debug"
+	"github.com/g4s8/envdoc/resolver"
+)
+
+// directive is an envdoc go:generate directive found in source file.
+type directive struct {
+	// File is a path of source file.
+	File string
+	// Line is a line number of directive.
+	Line int
+	// Args are envdoc arguments of directive.
+	Args []string
+}
+
+// generateSummary counts outputs of generate command.
+type generateSummary struct {
+	changed, unchanged, failed int
+}
+
+// generateCommand runs all envdoc go:generate directives of packages
+// matched by patterns in one process, it returns exit code.
+// Parsed packages and resolved types are shared between directives.
+func generateCommand(args []string, stdout, stderr io.Writer) int {
+	f := flag.NewFlagSet("envdoc generate", flag.ContinueOnError)
+	f.SetOutput(stderr)
+	var check, verbose, dbg bool
+	f.BoolVar(&check, "check", false, "Check that outputs are up to date without writing them")
+	f.BoolVar(&verbose, "v", false, "Print unchanged outputs too")
+	f.BoolVar(&dbg, "debug", false, "Enable debug output")
+	var noCache bool
+	f.BoolVar(&noCache, "no-cache", false, "Disable on-disk cache of parsed files")
+	if err := f.Parse(args); err != nil {
+		return 2
+	}
+	if os.Getenv("ENVDOC_CHECK") != "" {
+		check = true
+	}
+	patterns := f.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	dirs, err := findDirectives(patterns)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to find directives: %v\n", err)
+		return 2
+	}
+
+	cache := ast.NewParseCache()
+	resolvers := resolver.NewCache()
+	diskCache := newDiskCache(Config{NoCache: noCache})
+	var sum generateSummary
+	for _, d := range dirs {
+		pos := fmt.Sprintf("%s:%d", d.File, d.Line)
+		cfgs, err := directiveConfigs(d, check, dbg)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed    %s: %v\n", pos, err)
+			sum.failed++
+			continue
+		}
+		for _, cfg := range cfgs {
+			opts := []ast.ParserConfigOption{ast.WithParseCache(cache)}
+			if diskCache != nil && !cfg.NoCache {
+				opts = append(opts, ast.WithDiskCache(diskCache))
+			}
+			runDirective(cfg, pos, resolvers, opts, verbose, stdout, stderr, &sum)
+		}
+	}
+
+	if dbg {
+		hits, misses := cache.Stats()
+		fmt.Fprintf(stderr, "Parse cache: %d hits, %d misses\n", hits, misses)
+		if diskCache != nil {
+			hits, misses := diskCache.Stats()
+			fmt.Fprintf(stderr, "Disk cache: %d hits, %d misses\n", hits, misses)
+		}
+	}
+	fmt.Fprintf(stdout, "%d changed, %d unchanged, %d failed\n", sum.changed, sum.unchanged, sum.failed)
+	if sum.failed > 0 || check && sum.changed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// directiveConfigs parses directive arguments the same way as command line flags,
+// relative paths are resolved against the package dir, the same as go generate
+// runs directives.
+func directiveConfigs(d directive, check, dbg bool) ([]Config, error) {
+	var c Config
+	f := flag.NewFlagSet("envdoc", flag.ContinueOnError)
+	f.SetOutput(io.Discard)
+	if err := c.parseFlags(f, d.Args); err != nil {
+		return nil, err
+	}
+	c.ExecFile = filepath.Base(d.File)
+	c.ExecLine = d.Line
+	c.WorkDir = filepath.Dir(d.File)
+	c.resolvePaths()
+	c.Check = c.Check || check
+	c.Debug = c.Debug || dbg
+	return c.configs()
+}
+
+// runDirective generates outputs of one directive config and updates the summary.
+//
+//nolint:gocognit
+func runDirective(cfg Config, pos string, resolvers *resolver.Cache, opts []ast.ParserConfigOption, verbose bool,
+	stdout, stderr io.Writer, sum *generateSummary,
+) {
+	if cfg.Debug {
+		debug.Config.Enabled = true
+		cfg.fprint(stderr)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "failed    %s: invalid config: %v\n", pos, err)
+		sum.failed++
+		return
+	}
+	outCfgs, contents, err := generateOutputs(cfg, resolvers, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed    %s: %v\n", pos, err)
+		sum.failed += len(cfg.outputConfigs())
+		return
+	}
+	for i, oc := range outCfgs {
+		if isStdout(oc.OutFile) {
+			if err := writeOutput(oc, contents[i], stdout); err != nil {
+				fmt.Fprintf(stderr, "failed    %s: %v\n", pos, err)
+				sum.failed++
+			}
+			continue
+		}
+		out := oc.OutFile
+		changed, err := updateOutput(oc, contents[i], stdout)
+		switch {
+		case err != nil:
+			fmt.Fprintf(stderr, "failed    %s: %s: %v\n", pos, out, err)
+			sum.failed++
+		case changed && oc.Check:
+			fmt.Fprintf(stdout, "outdated  %s\n", out)
+			sum.changed++
+		case changed:
+			fmt.Fprintf(stdout, "changed   %s\n", out)
+			sum.changed++
+		default:
+			if verbose {
+				fmt.Fprintf(stdout, "unchanged %s\n", out)
+			}
+			sum.unchanged++
+		}
+	}
+}
+
+// findDirectives finds envdoc go:generate directives in Go files of packages
+// matched by patterns. Like go tool, ./... pattern skips vendor and testdata dirs,
+// and dirs starting with '.' or '_', see ast.SkipDirName.
+func findDirectives(patterns []string) ([]directive, error) {
+	var res []directive
+	for _, pattern := range patterns {
+		root := packageDir(pattern)
+		recursive := root != pattern
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path == root {
+					return nil
+				}
+				if !recursive || ast.SkipDirName(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			found, err := fileDirectives(path)
+			if err != nil {
+				return err
+			}
+			res = append(res, found...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk %q: %w", root, err)
+		}
+	}
+	return res, nil
+}
+
+// fileDirectives returns envdoc directives of source file,
+// like go generate it checks only lines starting with //go:generate.
+func fileDirectives(path string) ([]directive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	var res []directive
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !strings.HasPrefix(text, "//go:generate") {
+			continue
+		}
+		if args, ok := ast.ParseDirective(strings.TrimRight(text, " \t\r")); ok {
+			res = append(res, directive{File: path, Line: line, Args: args})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+)
+
+const generateTestSource = `package %s
+
+//go:generate envdoc -output %s -format dotenv
+type Config struct {
+	// Port doc.
+	Port int ` + "`env:\"PORT\"`" + `
+}
+`
+
+func writeGenerateTestPackage(t *testing.T, dir, pkg, out string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	src := fmt.Sprintf(generateTestSource, pkg, out)
+	if err := os.WriteFile(filepath.Join(dir, pkg+".go"), []byte(src), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+}
+
+func TestFileDirectives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.go")
+	src := "package a\n\n//go:generate envdoc -output a.md\n//go:generate stringer -type=A\n" +
+		"//go:generate go run github.com/g4s8/envdoc@latest -format dotenv -output .env\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	dirs, err := fileDirectives(path)
+	testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+	testutils.AssertFatal(t, len(dirs) == 2, "unexpected directives: %+v", dirs)
+	testutils.AssertError(t, dirs[0].Line == 3 && strings.Join(dirs[0].Args, " ") == "-output a.md",
+		"unexpected first directive: %+v", dirs[0])
+	testutils.AssertError(t, dirs[1].Line == 5 && strings.Join(dirs[1].Args, " ") == "-format dotenv -output .env",
+		"unexpected second directive: %+v", dirs[1])
+}
+
+func TestGenerateCommand(t *testing.T) {
+	root := t.TempDir()
+	writeGenerateTestPackage(t, filepath.Join(root, "a"), "a", "a.env")
+	writeGenerateTestPackage(t, filepath.Join(root, "b"), "b", "b.env")
+	writeGenerateTestPackage(t, filepath.Join(root, "testdata"), "c", "c.env")
+	t.Chdir(root)
+
+	run := func(args ...string) (int, string) {
+		var stdout, stderr strings.Builder
+		code := generateCommand(args, &stdout, &stderr)
+		return code, stdout.String() + stderr.String()
+	}
+
+	code, out := run()
+	testutils.AssertFatal(t, code == 0, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, strings.Contains(out, "2 changed, 0 unchanged, 0 failed"), "unexpected output: %s", out)
+	_, err := os.Stat(filepath.Join(root, "testdata", "c.env"))
+	testutils.AssertError(t, os.IsNotExist(err), "testdata dir should be skipped")
+	content, err := os.ReadFile(filepath.Join(root, "a", "a.env"))
+	testutils.AssertFatal(t, err == nil, "read output: %v", err)
+	testutils.AssertError(t, strings.Contains(string(content), "PORT="), "unexpected content: %s", content)
+
+	code, out = run("-check")
+	testutils.AssertError(t, code == 0, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, strings.Contains(out, "0 changed, 2 unchanged, 0 failed"), "unexpected output: %s", out)
+
+	if err := os.WriteFile(filepath.Join(root, "b", "b.env"), []byte("outdated\n"), 0o600); err != nil {
+		t.Fatalf("write output: %v", err)
+	}
+	code, out = run("-check", "./...")
+	testutils.AssertError(t, code == 1, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, strings.Contains(out, "outdated  "+filepath.Join("b", "b.env")), "unexpected output: %s", out)
+	testutils.AssertError(t, strings.Contains(out, "1 changed, 1 unchanged, 0 failed"), "unexpected output: %s", out)
+
+	code, out = run("./a")
+	testutils.AssertError(t, code == 0, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, strings.Contains(out, "0 changed, 1 unchanged, 0 failed"), "unexpected output: %s", out)
+}
+
+func TestGenerateCommandWorkDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "svc", "api")
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	src := "package api\n\n//go:generate envdoc -output docs/env.json -format json\ntype Config struct {\n" +
+		"\t// Port doc.\n\tPort int `env:\"PORT\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "api.go"), []byte(src), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	t.Chdir(root)
+
+	var stdout, stderr strings.Builder
+	code := generateCommand(nil, &stdout, &stderr)
+	testutils.AssertFatal(t, code == 0, "unexpected exit code %d: %s%s", code, stdout.String(), stderr.String())
+	testutils.AssertError(t, strings.Contains(stdout.String(), "changed   "+filepath.Join("svc", "api", "docs", "env.json")),
+		"unexpected output: %s", stdout.String())
+	wd, err := os.Getwd()
+	testutils.AssertFatal(t, err == nil, "get working dir: %v", err)
+	testutils.AssertError(t, filepath.Base(wd) == filepath.Base(root), "working dir is changed: %s", wd)
+
+	// positions are relative to the package dir, the same as go generate output
+	content, err := os.ReadFile(filepath.Join(dir, "docs", "env.json"))
+	testutils.AssertFatal(t, err == nil, "read output: %v", err)
+	testutils.AssertError(t, strings.Contains(string(content), `"file": "api.go"`), "unexpected content: %s", content)
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+)
+
+func TestNewDiskCache(t *testing.T) {
+	t.Setenv("ENVDOC_CACHE", t.TempDir())
+	testutils.AssertError(t, newDiskCache(Config{NoCache: true}) == nil, "expected disabled cache with -no-cache flag")
+
+	t.Setenv("ENVDOC_CACHE", "off")
+	testutils.AssertError(t, newDiskCache(Config{}) == nil, "expected disabled cache with ENVDOC_CACHE=off")
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/g4s8/envdoc/ast"
 	"github.com/g4s8/envdoc/debug"
+	"github.com/g4s8/envdoc/envdoc"
 	"github.com/g4s8/envdoc/resolver"
 	"github.com/g4s8/envdoc/types"
 )
@@ -16,11 +17,15 @@ type Renderer interface {
 
 type Generator struct {
 	parser    *ast.Parser
-	converter *Converter
+	converter *envdoc.Converter
 	renderer  Renderer
+	// resolvers is an optional cache of type resolvers shared between generators
+	resolvers *resolver.Cache
+	// log is an optional logger of resolved types
+	log debug.Logger
 }
 
-func NewGenerator(parser *ast.Parser, converter *Converter, renderer Renderer) *Generator {
+func NewGenerator(parser *ast.Parser, converter *envdoc.Converter, renderer Renderer) *Generator {
 	return &Generator{
 		parser:    parser,
 		converter: converter,
@@ -29,19 +34,59 @@ func NewGenerator(parser *ast.Parser, converter *Converter, renderer Renderer) *
 }
 
 func (g *Generator) Generate(dir string, out io.Writer) error {
+	return g.GenerateOutputs(dir, []Output{{Renderer: g.renderer, Writer: out}})
+}
+
+// Output is a renderer with its destination writer.
+type Output struct {
+	Renderer Renderer
+	Writer   io.Writer
+}
+
+// Scopes parses dir and converts parsed types to documentation scopes.
+func (g *Generator) Scopes(dir string) ([]*types.EnvScope, error) {
 	files, err := g.parser.Parse(dir)
 	if err != nil {
-		return fmt.Errorf("parse dir: %w", err)
+		return nil, fmt.Errorf("parse dir: %w", err)
 	}
 
-	res := resolver.ResolveAllTypes(files)
+	res, err := g.resolveTypes(dir, files)
+	if err != nil {
+		return nil, err
+	}
 	debug.PrintDebug(res)
 
 	scopes := g.converter.ScopesFromFiles(res, files)
 	printScopesTree(scopes)
+	return scopes, nil
+}
+
+// resolveTypes resolves types of parsed files, the resolver is shared
+// with other generators parsing the same sources if resolver cache is set.
+func (g *Generator) resolveTypes(dir string, files []*ast.FileSpec) (*resolver.TypeResolver, error) {
+	opt := resolver.WithLogger(g.log)
+	if g.resolvers == nil {
+		return resolver.ResolveAllTypes(files, opt), nil
+	}
+	key, err := g.parser.CacheKey(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve types: %w", err)
+	}
+	return g.resolvers.ResolveAllTypes(key, files, opt), nil
+}
+
+// GenerateOutputs parses and converts dir once, then renders documentation
+// to each output.
+func (g *Generator) GenerateOutputs(dir string, outputs []Output) error {
+	scopes, err := g.Scopes(dir)
+	if err != nil {
+		return err
+	}
 
-	if err := g.renderer.Render(scopes, out); err != nil {
-		return fmt.Errorf("render: %w", err)
+	for _, out := range outputs {
+		if err := out.Renderer.Render(scopes, out.Writer); err != nil {
+			return fmt.Errorf("render: %w", err)
+		}
 	}
 
 	return nil
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/g4s8/envdoc/ast"
+	"github.com/g4s8/envdoc/envdoc"
 	"github.com/g4s8/envdoc/render"
 	"github.com/g4s8/envdoc/types"
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -43,7 +44,7 @@ func TestGenerator(t *testing.T) {
 			dir := extractTxtar(t, ar)
 
 			p := ast.NewParser("*", spec.TypeName)
-			conv := NewConverter(types.TargetTypeCaarlos0, ConverterOpts{
+			conv := envdoc.NewConverter(types.TargetTypeCaarlos0, envdoc.ConverterOpts{
 				EnvPrefix:     spec.EnvPrefix,
 				TagName:       "env",
 				TagDefault:    "envDefault",
@@ -167,3 +168,48 @@ func parseTestSpec(t *testing.T, data string) GenTestSpec {
 	}
 	return res
 }
+
+type countingRenderer struct {
+	calls int
+	text  string
+}
+
+func (r *countingRenderer) Render(scopes []*types.EnvScope, out io.Writer) error {
+	r.calls++
+	_, err := io.WriteString(out, r.text+scopes[0].Name)
+	return err
+}
+
+func TestGeneratorOutputs(t *testing.T) {
+	dir := t.TempDir()
+	src := "package main\n\n// Config doc.\ntype Config struct {\n\t// Port doc.\n\tPort int `env:\"PORT\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o600); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	p := ast.NewParser("*", "Config")
+	conv := envdoc.NewConverter(types.TargetTypeCaarlos0, envdoc.ConverterOpts{
+		TagName:    "env",
+		TagDefault: "envDefault",
+	})
+	gen := NewGenerator(p, conv, nil)
+
+	first, second := &countingRenderer{text: "first "}, &countingRenderer{text: "second "}
+	var out1, out2 bytes.Buffer
+	err := gen.GenerateOutputs(dir, []Output{
+		{Renderer: first, Writer: &out1},
+		{Renderer: second, Writer: &out2},
+	})
+	if err != nil {
+		t.Fatalf("failed to generate: %s", err)
+	}
+	if first.calls != 1 || second.calls != 1 {
+		t.Fatalf("unexpected render calls: %d, %d", first.calls, second.calls)
+	}
+	if out1.String() != "first Config" {
+		t.Errorf("unexpected first output: %q", out1.String())
+	}
+	if out2.String() != "second Config" {
+		t.Errorf("unexpected second output: %q", out2.String())
+	}
+}
@@ -3,87 +3,105 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/g4s8/envdoc/ast"
 	"github.com/g4s8/envdoc/debug"
-	"github.com/g4s8/envdoc/edit"
-	"github.com/g4s8/envdoc/render"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		os.Exit(generateCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validateCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(diffCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "drift" {
+		os.Exit(driftCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		code := serveCommand(ctx, os.Args[2:], os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
 	var cfg Config
-	if err := cfg.Load(); err != nil {
+	cfgs, err := cfg.Load()
+	if err != nil {
 		fatal("Failed to load config: %v", err)
 	}
+	watch := len(cfgs) > 0 && cfgs[0].Watch
+	for _, cfg := range cfgs {
+		// watch mode keeps running after failed generation,
+		// outputs are regenerated when sources are fixed
+		if !run(cfg) && !watch {
+			os.Exit(1)
+		}
+	}
+	if watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := newWatcher(cfgs, os.Stdout).run(ctx); err != nil {
+			fatal("Failed to watch: %v", err)
+		}
+	}
+}
+
+// run generates documentation for outputs of one config, errors are printed
+// to stderr, it returns false if config is invalid or any output failed.
+func run(cfg Config) bool {
 	if cfg.Debug {
 		debug.Config.Enabled = true
 		cfg.fprint(os.Stdout)
 	}
 	if err := cfg.Validate(); err != nil {
-		fatal("Invalid config: %v", err)
-	}
-
-	parser := ast.NewParser(cfg.FileGlob, cfg.TypeGlob,
-		ast.WithDebug(cfg.Debug),
-		ast.WithExecConfig(cfg.ExecFile, cfg.ExecLine))
-	converter := NewConverter(cfg.Target, ConverterOpts{
-		EnvPrefix:       cfg.EnvPrefix,
-		TagName:         cfg.TagName,
-		TagDefault:      cfg.TagDefault,
-		RequiredIfNoDef: cfg.RequiredIfNoDef,
-		UseFieldNames:   cfg.FieldNames,
-	})
-	renderer := render.NewRenderer(cfg.OutFormat, cfg.NoStyles)
-	gen := NewGenerator(parser, converter, renderer)
-
-	// Branch based on mode
-	if cfg.Edit {
-		generateEdit(gen, cfg)
-	} else {
-		generateNormal(gen, cfg)
+		if cfg.Profile != "" {
+			fmt.Fprintf(os.Stderr, "Invalid config of %q profile: %v\n", cfg.Profile, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+		}
+		return false
 	}
-}
 
-// generateEdit generates documentation in edit mode, replacing content between markers
-func generateEdit(gen *Generator, cfg Config) {
-	var buf bytes.Buffer
-	if err := gen.Generate(cfg.Dir, &buf); err != nil {
-		fatal("Failed to generate: %v", err)
+	var opts []ast.ParserConfigOption
+	cache := newDiskCache(cfg)
+	if cache != nil {
+		opts = append(opts, ast.WithDiskCache(cache))
 	}
-
-	editor := edit.NewEditor(cfg.OutFile)
-	if err := editor.ReplaceSection(buf.Bytes()); err != nil {
-		fatal("Failed to edit file: %v", err)
+	outCfgs, contents, err := generateOutputs(cfg, nil, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate: %v\n", err)
+		return false
 	}
-
-	if cfg.Debug {
-		fmt.Fprintf(os.Stderr, "Successfully updated %s\n", cfg.OutFile)
+	if cfg.Debug && cache != nil {
+		hits, misses := cache.Stats()
+		fmt.Fprintf(os.Stderr, "Cache: %d hits, %d misses\n", hits, misses)
 	}
-}
 
-// generateNormal generates documentation in normal mode, writing to output file
-func generateNormal(gen *Generator, cfg Config) {
-	out, err := os.Create(cfg.OutFile)
-	if err != nil {
-		fatal("Failed to open output file: %v", err)
-	}
-	defer func() {
-		if err := out.Close(); err != nil {
-			fatal("Failed to close output file: %v", err)
+	ok := true
+	for i, oc := range outCfgs {
+		if err := writeOutput(oc, contents[i], os.Stdout); err != nil {
+			if oc.Check {
+				fmt.Fprintf(os.Stderr, "Check failed: %v\n", err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", oc.OutFile, err)
+			}
+			ok = false
+			continue
+		}
+		if cfg.Debug && !oc.Check && !isStdout(oc.OutFile) {
+			fmt.Fprintf(os.Stderr, "Successfully updated %s\n", oc.OutFile)
 		}
-	}()
-
-	buf := bufio.NewWriter(out)
-	if err := gen.Generate(cfg.Dir, buf); err != nil {
-		fatal("Failed to generate: %v", err)
-	}
-	if err := buf.Flush(); err != nil {
-		fatal("Failed to flush output: %v", err)
 	}
+	return ok
 }
 
 func fatal(format string, args ...interface{}) {
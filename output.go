@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/g4s8/envdoc/edit"
+	"github.com/g4s8/envdoc/types"
+)
+
+// OutputSpec is an output of -output flag in fmt=path or fmt:edit=path form.
+type OutputSpec struct {
+	// Format of the output.
+	Format types.OutFormat
+	// File is the output file path.
+	File string
+	// Edit enables edit mode for this output.
+	Edit bool
+}
+
+func (s OutputSpec) String() string {
+	if s.Edit {
+		return fmt.Sprintf("%s:edit=%s", s.Format, s.File)
+	}
+	return fmt.Sprintf("%s=%s", s.Format, s.File)
+}
+
+// parseOutputSpec parses fmt=path or fmt:edit=path output,
+// it returns false if s is a plain file path.
+func parseOutputSpec(s string) (OutputSpec, bool) {
+	name, file, ok := strings.Cut(s, "=")
+	if !ok || file == "" {
+		return OutputSpec{}, false
+	}
+	format, mode, _ := strings.Cut(name, ":")
+	if mode != "" && mode != "edit" {
+		return OutputSpec{}, false
+	}
+	if !isOutFormat(types.OutFormat(format)) {
+		return OutputSpec{}, false
+	}
+	return OutputSpec{Format: types.OutFormat(format), File: file, Edit: mode == "edit"}, true
+}
+
+func isOutFormat(f types.OutFormat) bool {
+	for _, known := range types.OutFormats {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// outputFlag is a repeatable -output flag: it's either a plain path
+// for -format output, or fmt=path pair for multiple outputs.
+type outputFlag struct {
+	c *Config
+}
+
+func (f outputFlag) String() string {
+	if f.c == nil {
+		return ""
+	}
+	specs := make([]string, 0, len(f.c.Outputs)+1)
+	if f.c.OutFile != "" {
+		specs = append(specs, f.c.OutFile)
+	}
+	for _, o := range f.c.Outputs {
+		specs = append(specs, o.String())
+	}
+	return strings.Join(specs, ",")
+}
+
+func (f outputFlag) Set(s string) error {
+	if spec, ok := parseOutputSpec(s); ok {
+		f.c.Outputs = append(f.c.Outputs, spec)
+		return nil
+	}
+	if f.c.OutFile != "" {
+		return fmt.Errorf("output file is already set to %q, use fmt=path form for multiple outputs", f.c.OutFile)
+	}
+	f.c.OutFile = s
+	return nil
+}
+
+// outputConfigs returns a config for each output of -output flags.
+func (c Config) outputConfigs() []Config {
+	if len(c.Outputs) == 0 {
+		return []Config{c}
+	}
+	res := make([]Config, len(c.Outputs))
+	for i, o := range c.Outputs {
+		oc := c
+		oc.OutFile = o.File
+		oc.OutFormat = o.Format
+		oc.Edit = c.Edit || o.Edit
+		oc.Outputs = nil
+		res[i] = oc
+	}
+	return res
+}
+
+// writeOutput writes generated content to output file of cfg or to stdout
+// if output is "-" or empty: in check mode it only compares content
+// and writes diff to stdout, in edit mode it replaces the section between markers.
+func writeOutput(cfg Config, content []byte, stdout io.Writer) error {
+	switch {
+	case cfg.Check:
+		return checkOutputFile(cfg, content, stdout)
+	case cfg.Edit:
+		return editOutputFile(cfg, content)
+	case isStdout(cfg.OutFile):
+		if _, err := stdout.Write(content); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+		return nil
+	default:
+		if err := edit.WriteFileAtomic(cfg.OutFile, content, 0o644); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		return nil
+	}
+}
+
+func (c Config) editor() (*edit.Editor, error) {
+	style, err := c.editMarkerStyle()
+	if err != nil {
+		return nil, err
+	}
+	return edit.NewEditor(c.OutFile, edit.WithMarkerStyle(style), edit.WithSection(c.Section)), nil
+}
+
+func editOutputFile(cfg Config, content []byte) error {
+	editor, err := cfg.editor()
+	if err != nil {
+		return err
+	}
+	if err := editor.ReplaceSection(content); err != nil {
+		return fmt.Errorf("edit file: %w", err)
+	}
+	return nil
+}
+
+func checkOutputFile(cfg Config, content []byte, stdout io.Writer) error {
+	current, expected, err := outputContent(cfg, content)
+	if err != nil {
+		return err
+	}
+	return checkOutput(cfg.OutFile, current, expected, stdout)
+}
+
+// outputContent returns current content of output file and expected content
+// with generated documentation. Current content is nil if file doesn't exist.
+func outputContent(cfg Config, content []byte) (current, expected []byte, err error) {
+	if cfg.Edit {
+		editor, err := cfg.editor()
+		if err != nil {
+			return nil, nil, err
+		}
+		current, expected, err = editor.Preview(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("check file: %w", err)
+		}
+		return current, expected, nil
+	}
+	current, err = os.ReadFile(cfg.OutFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("read output file: %w", err)
+	}
+	return current, content, nil
+}
+
+// updateOutput writes generated content to output file only if it's changed,
+// in check mode it writes diff to stdout instead. It returns true if output
+// file was changed or it's out of date in check mode.
+func updateOutput(cfg Config, content []byte, stdout io.Writer) (bool, error) {
+	current, expected, err := outputContent(cfg, content)
+	if err != nil {
+		return false, err
+	}
+	if current != nil && bytes.Equal(current, expected) {
+		return false, nil
+	}
+	if cfg.Check {
+		if err := checkOutput(cfg.OutFile, current, expected, stdout); err != nil && !errors.Is(err, ErrOutdated) {
+			return false, err
+		}
+		return true, nil
+	}
+	if err := writeOutput(cfg, content, stdout); err != nil {
+		return false, err
+	}
+	return true, nil
+}
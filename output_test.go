@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func TestParseOutputSpec(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		expect OutputSpec
+		ok     bool
+	}{
+		{"markdown=ENV.md", OutputSpec{Format: types.OutFormatMarkdown, File: "ENV.md"}, true},
+		{"dotenv=.env.example", OutputSpec{Format: types.OutFormatEnv, File: ".env.example"}, true},
+		{"markdown:edit=README.md", OutputSpec{Format: types.OutFormatMarkdown, File: "README.md", Edit: true}, true},
+		{"ENV.md", OutputSpec{}, false},
+		{"dir/a=b.md", OutputSpec{}, false},
+		{"markdown=", OutputSpec{}, false},
+		{"markdown:copy=ENV.md", OutputSpec{}, false},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			spec, ok := parseOutputSpec(tc.in)
+			testutils.AssertFatal(t, ok == tc.ok, "unexpected ok: %v", ok)
+			testutils.AssertError(t, spec == tc.expect, "unexpected spec: %+v", spec)
+		})
+	}
+}
+
+func TestOutputFlag(t *testing.T) {
+	t.Run("outputs", func(t *testing.T) {
+		var c Config
+		f := outputFlag{&c}
+		testutils.AssertFatal(t, f.Set("markdown=ENV.md") == nil, "unexpected error")
+		testutils.AssertFatal(t, f.Set("json:edit=env.json") == nil, "unexpected error")
+		testutils.AssertError(t, c.OutFile == "", "unexpected OutFile: %q", c.OutFile)
+		testutils.AssertFatal(t, len(c.Outputs) == 2, "unexpected outputs: %v", c.Outputs)
+		testutils.AssertError(t, f.String() == "markdown=ENV.md,json:edit=env.json", "unexpected string: %q", f.String())
+
+		c.Edit = false
+		cfgs := c.outputConfigs()
+		testutils.AssertFatal(t, len(cfgs) == 2, "unexpected configs: %d", len(cfgs))
+		testutils.AssertError(t, cfgs[0].OutFile == "ENV.md" && cfgs[0].OutFormat == types.OutFormatMarkdown && !cfgs[0].Edit,
+			"unexpected first config: %+v", cfgs[0])
+		testutils.AssertError(t, cfgs[1].OutFile == "env.json" && cfgs[1].OutFormat == types.OutFormatJSON && cfgs[1].Edit,
+			"unexpected second config: %+v", cfgs[1])
+	})
+	t.Run("plain path", func(t *testing.T) {
+		var c Config
+		f := outputFlag{&c}
+		testutils.AssertFatal(t, f.Set("ENV.md") == nil, "unexpected error")
+		testutils.AssertError(t, c.OutFile == "ENV.md", "unexpected OutFile: %q", c.OutFile)
+		testutils.AssertError(t, f.Set("other.md") != nil, "expected error for second plain path")
+		cfgs := c.outputConfigs()
+		testutils.AssertError(t, len(cfgs) == 1 && cfgs[0].OutFile == "ENV.md", "unexpected configs: %+v", cfgs)
+	})
+	t.Run("validate", func(t *testing.T) {
+		c := Config{OutFile: "ENV.md", Outputs: []OutputSpec{{Format: types.OutFormatEnv, File: ".env"}}}
+		testutils.AssertError(t, c.Validate() != nil, "expected error for mixed outputs")
+		c.OutFile = ""
+		testutils.AssertError(t, c.Validate() == nil, "unexpected error: %v", c.Validate())
+		c.Outputs = append(c.Outputs, OutputSpec{Format: types.OutFormatJSON, File: "env.json", Edit: true})
+		testutils.AssertError(t, c.Validate() != nil, "expected error for json edit output")
+	})
+}
+
+func TestWriteOutput(t *testing.T) {
+	dir := t.TempDir()
+	t.Run("write", func(t *testing.T) {
+		cfg := Config{OutFile: filepath.Join(dir, "ENV.md"), OutFormat: types.OutFormatMarkdown}
+		testutils.AssertFatal(t, writeOutput(cfg, []byte("doc\n"), nil) == nil, "unexpected error")
+		data, err := os.ReadFile(cfg.OutFile)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, string(data) == "doc\n", "unexpected content: %q", data)
+	})
+	t.Run("edit", func(t *testing.T) {
+		cfg := Config{OutFile: filepath.Join(dir, ".env.example"), OutFormat: types.OutFormatEnv, Edit: true}
+		err := os.WriteFile(cfg.OutFile, []byte("A=1\n# envdoc:begin\n# envdoc:end\n"), 0o600)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertFatal(t, writeOutput(cfg, []byte("B=2\n"), nil) == nil, "unexpected error")
+		data, err := os.ReadFile(cfg.OutFile)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, string(data) == "A=1\n# envdoc:begin\nB=2\n# envdoc:end\n", "unexpected content: %q", data)
+	})
+	t.Run("stdout", func(t *testing.T) {
+		var out strings.Builder
+		cfg := Config{OutFile: "-", OutFormat: types.OutFormatMarkdown}
+		testutils.AssertFatal(t, writeOutput(cfg, []byte("doc\n"), &out) == nil, "unexpected error")
+		cfg.OutFile = ""
+		testutils.AssertFatal(t, writeOutput(cfg, []byte("doc\n"), &out) == nil, "unexpected error")
+		testutils.AssertError(t, out.String() == "doc\ndoc\n", "unexpected output: %q", out.String())
+	})
+	t.Run("check", func(t *testing.T) {
+		cfg := Config{OutFile: filepath.Join(dir, "ENV.md"), OutFormat: types.OutFormatMarkdown, Check: true}
+		var out strings.Builder
+		testutils.AssertError(t, writeOutput(cfg, []byte("doc\n"), &out) == nil, "unexpected error")
+		err := writeOutput(cfg, []byte("new doc\n"), &out)
+		testutils.AssertError(t, errors.Is(err, ErrOutdated), "unexpected error: %v", err)
+		testutils.AssertError(t, strings.Contains(out.String(), "+new doc"), "unexpected diff: %s", out.String())
+		data, err := os.ReadFile(cfg.OutFile)
+		testutils.AssertFatal(t, err == nil, "unexpected error: %v", err)
+		testutils.AssertError(t, string(data) == "doc\n", "check mode must not write file: %q", data)
+	})
+}
@@ -1,72 +1,207 @@
 package render
 
-import "github.com/g4s8/envdoc/types"
+import (
+	"go/format"
 
-type renderItemConfig struct {
-	SeparatorFormat  string
-	SeparatorDefault string
-	OptRequired      string
-	OptExpand        string
-	OptNonEmpty      string
-	OptFromFile      string
-	EnvDefaultFormat string
+	"github.com/g4s8/envdoc/types"
+)
+
+// TemplateItemConfig is a format specific options used
+// by `item.options` helper template.
+type TemplateItemConfig struct {
+	SeparatorFormat       string
+	SeparatorDefault      string
+	OptRequired           string
+	OptExpand             string
+	OptNonEmpty           string
+	OptFromFile           string
+	EnvDefaultFormat      string
+	BuildConstraintFormat string
+	// Literal is a markup of inline literal values: "rst" or "asciidoc",
+	// formats of values don't have literal markup if it's set.
+	Literal string
+}
+
+// TemplateConfig is a configuration of output format passed to templates.
+type TemplateConfig struct {
+	Item TemplateItemConfig
 }
-type renderConfig struct {
-	Item renderItemConfig
+
+// formatConfig is a renderer configuration of output format.
+type formatConfig struct {
+	item TemplateItemConfig
 	tmpl template
+	// escape is applied to all text values before rendering, if set.
+	escape func(string) string
+	// format is applied to rendered output, if set.
+	format func([]byte) ([]byte, error)
+}
+
+func (c formatConfig) templateConfig() TemplateConfig {
+	return TemplateConfig{Item: c.item}
 }
 
-var configs = map[types.OutFormat]renderConfig{
+func (c formatConfig) escapeText(s string) string {
+	if c.escape == nil {
+		return s
+	}
+	return c.escape(s)
+}
+
+var configs = map[types.OutFormat]formatConfig{
 	types.OutFormatMarkdown: {
-		Item: renderItemConfig{
-			SeparatorFormat:  "separated by `%s`",
-			SeparatorDefault: "comma-separated",
-			OptRequired:      "**required**",
-			OptExpand:        "expand",
-			OptFromFile:      "from-file",
-			OptNonEmpty:      "non-empty",
-			EnvDefaultFormat: "default: `%s`",
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by `%s`",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "**required**",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: `%s`",
+			BuildConstraintFormat: "build: `%s`",
 		},
 		tmpl: newTmplText("markdown.tmpl"),
 	},
 	types.OutFormatHTML: {
-		Item: renderItemConfig{
-			SeparatorFormat:  `separated by "<code>%s</code>"`,
-			SeparatorDefault: "comma-separated",
-			OptRequired:      "<strong>required</strong>",
-			OptExpand:        "expand",
-			OptFromFile:      "from-file",
-			OptNonEmpty:      "non-empty",
-			EnvDefaultFormat: "default: <code>%s</code>",
+		item: TemplateItemConfig{
+			SeparatorFormat:       `separated by "<code>%s</code>"`,
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "<strong>required</strong>",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: <code>%s</code>",
+			BuildConstraintFormat: "build: <code>%s</code>",
 		},
 		tmpl: newTmplText("html.tmpl"),
 	},
 	types.OutFormatTxt: {
-		Item: renderItemConfig{
-			SeparatorFormat:  "separated by `%s`",
-			SeparatorDefault: "comma-separated",
-			OptRequired:      "required",
-			OptExpand:        "expand",
-			OptFromFile:      "from-file",
-			OptNonEmpty:      "non-empty",
-			EnvDefaultFormat: "default: `%s`",
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by `%s`",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "required",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: `%s`",
+			BuildConstraintFormat: "build: `%s`",
 		},
 		tmpl: newTmplText("plaintext.tmpl"),
 	},
 	types.OutFormatEnv: {
-		Item: renderItemConfig{
-			SeparatorFormat:  "separated by '%s'",
-			SeparatorDefault: "comma-separated",
-			OptRequired:      "required",
-			OptExpand:        "expand",
-			OptFromFile:      "from-file",
-			OptNonEmpty:      "non-empty",
-			EnvDefaultFormat: "default: '%s'",
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by '%s'",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "required",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: '%s'",
+			BuildConstraintFormat: "build: '%s'",
 		},
 		tmpl: newTmplText("dotenv.tmpl"),
 	},
 	types.OutFormatJSON: {
-		Item: renderItemConfig{},
+		item: TemplateItemConfig{},
 		tmpl: newTmplText("json.tmpl"),
 	},
+	types.OutFormatCompose: {
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by '%s'",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "required",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: '%s'",
+			BuildConstraintFormat: "build: '%s'",
+		},
+		tmpl: newTmplText("compose.tmpl"),
+	},
+	types.OutFormatDocker: {
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by '%s'",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "required",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: '%s'",
+			BuildConstraintFormat: "build: '%s'",
+		},
+		tmpl: newTmplText("dockerfile.tmpl"),
+	},
+	types.OutFormatSystemd: {
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by '%s'",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "required",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: '%s'",
+			BuildConstraintFormat: "build: '%s'",
+		},
+		tmpl: newTmplText("systemd.tmpl"),
+	},
+	types.OutFormatShell: {
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by '%s'",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "required",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: '%s'",
+			BuildConstraintFormat: "build: '%s'",
+		},
+		tmpl: newTmplText("shell.tmpl"),
+	},
+	types.OutFormatMan: {
+		item: TemplateItemConfig{
+			SeparatorFormat:       `separated by "%s"`,
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "required",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: %s",
+			BuildConstraintFormat: "build: %s",
+		},
+		tmpl:   newTmplText("man.tmpl"),
+		escape: escapeRoff,
+	},
+	types.OutFormatAsciiDoc: {
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by %s",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "*required*",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: %s",
+			BuildConstraintFormat: "build: %s",
+			Literal:               "asciidoc",
+		},
+		tmpl: newTmplText("asciidoc.tmpl"),
+	},
+	types.OutFormatRST: {
+		item: TemplateItemConfig{
+			SeparatorFormat:       "separated by %s",
+			SeparatorDefault:      "comma-separated",
+			OptRequired:           "**required**",
+			OptExpand:             "expand",
+			OptFromFile:           "from-file",
+			OptNonEmpty:           "non-empty",
+			EnvDefaultFormat:      "default: %s",
+			BuildConstraintFormat: "build: %s",
+			Literal:               "rst",
+		},
+		tmpl: newTmplText("rst.tmpl"),
+	},
+	types.OutFormatGo: {
+		item:   TemplateItemConfig{},
+		tmpl:   newTmplText("go.tmpl"),
+		format: format.Source,
+	},
 }
@@ -0,0 +1,162 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/g4s8/envdoc/types"
+)
+
+// TemplateSection is a documentation section for one type (scope).
+type TemplateSection struct {
+	// Name of the section, usually the type name.
+	Name string `json:"name,omitempty"`
+	// Doc is a documentation text of the section.
+	Doc string `json:"doc,omitempty"`
+	// Items of the section.
+	Items []TemplateItem `json:"items,omitempty"`
+}
+
+// TemplateItem is a documentation item for one environment variable,
+// or a group of variables if EnvName is empty.
+type TemplateItem struct {
+	// EnvName is a name of environment variable.
+	EnvName string `json:"env_name,omitempty"`
+	// Doc is a documentation text.
+	Doc string `json:"doc,omitempty"`
+	// Type is a Go type name of the variable field.
+	Type string `json:"type,omitempty"`
+	// EnvDefault is a default value.
+	EnvDefault string `json:"env_default,omitempty"`
+	// EnvSeparator is a separator of array values.
+	EnvSeparator string `json:"env_separator,omitempty"`
+
+	// Required is true if the variable is required.
+	Required bool `json:"required,omitempty"`
+	// Expand is true if the variable value is expanded.
+	Expand bool `json:"expand,omitempty"`
+	// NonEmpty is true if the variable can't be empty.
+	NonEmpty bool `json:"non_empty,omitempty"`
+	// FromFile is true if the variable value is a path to file with actual value.
+	FromFile bool `json:"from_file,omitempty"`
+	// BuildConstraint is a build constraint expression, the variable
+	// exists only in builds matching it.
+	BuildConstraint string `json:"build_constraint,omitempty"`
+	// File is a path of source file with the variable declaration.
+	File string `json:"file,omitempty"`
+	// Line is a line number of the variable declaration in File.
+	Line int `json:"line,omitempty"`
+	// SourceLink is a link to the variable declaration in source file,
+	// it's set only if renderer has a source link function.
+	SourceLink string `json:"source_link,omitempty"`
+
+	// Children items of the group.
+	Children []TemplateItem `json:"children,omitempty"`
+	// Indent level of the item, starts from 1 for top-level items.
+	Indent int `json:"-"`
+}
+
+// Secret is true if the variable name looks like a name of a secret
+// value: password, token, key etc.
+func (i TemplateItem) Secret() bool {
+	for _, part := range strings.Split(strings.ToUpper(i.EnvName), "_") {
+		if secretNameParts[part] {
+			return true
+		}
+	}
+	return false
+}
+
+var secretNameParts = map[string]bool{
+	"SECRET": true, "SECRETS": true, "PASSWORD": true, "PASSWD": true, "PASS": true,
+	"TOKEN": true, "KEY": true, "APIKEY": true, "CREDENTIAL": true, "CREDENTIALS": true,
+	"PRIVATE": true, "DSN": true,
+}
+
+// IndentChildren returns children items with indent level increased by indentInc.
+func (i TemplateItem) IndentChildren(indentInc int) []TemplateItem {
+	indent := i.Indent + indentInc
+	res := make([]TemplateItem, len(i.Children))
+	for j, child := range i.Children {
+		child.Indent = indent
+		res[j] = child
+	}
+	return res
+}
+
+// TemplateData is a data passed to the template.
+//
+// It's a stable data model for custom templates (see -template flag),
+// fields are not removed or renamed between minor releases.
+type TemplateData struct {
+	// Title of the document.
+	Title string
+	// Sections is a list of documented types.
+	Sections []TemplateSection
+	// Styles is true if styles should be rendered (HTML format).
+	Styles bool
+	// Service is a docker-compose service name.
+	Service string
+	// SectionOnly is true if only document content should be rendered without page wrapper:
+	// ENVIRONMENT section for man format and page body for HTML format.
+	SectionOnly bool
+	// Interactive is true if HTML page should have a search box and filters.
+	Interactive bool
+	// Package is a Go package name of go format.
+	Package string
+	// Config of the current output format.
+	Config TemplateConfig
+}
+
+// sourceLinkFunc returns a link to source file position.
+type sourceLinkFunc func(file string, line int) string
+
+func newTemplateData(scopes []*types.EnvScope, cfg formatConfig, noStyles bool, link sourceLinkFunc) TemplateData {
+	res := TemplateData{
+		Sections: make([]TemplateSection, len(scopes)),
+		Styles:   !noStyles,
+		Config:   cfg.templateConfig(),
+	}
+	res.Title = "Environment Variables"
+	for i, scope := range scopes {
+		section := TemplateSection{
+			Name:  cfg.escapeText(scope.Name),
+			Doc:   cfg.escapeText(scope.Doc),
+			Items: make([]TemplateItem, len(scope.Vars)),
+		}
+		for j, item := range scope.Vars {
+			item := newTemplateItem(item, cfg.escapeText, link)
+			item.Indent = 1
+			section.Items[j] = item
+		}
+		res.Sections[i] = section
+	}
+	return res
+}
+
+func newTemplateItem(item *types.EnvDocItem, escape func(string) string, link sourceLinkFunc) TemplateItem {
+	children := make([]TemplateItem, len(item.Children))
+	for i, child := range item.Children {
+		children[i] = newTemplateItem(child, escape, link)
+	}
+	var sourceLink string
+	if link != nil && item.File != "" {
+		sourceLink = escape(link(item.File, item.Line))
+	}
+	return TemplateItem{
+		EnvName:      escape(item.Name),
+		Doc:          escape(item.Doc),
+		Type:         escape(item.Type),
+		EnvDefault:   escape(item.Opts.Default),
+		EnvSeparator: escape(item.Opts.Separator),
+		Required:     item.Opts.Required,
+		Expand:       item.Opts.Expand,
+		NonEmpty:     item.Opts.NonEmpty,
+		FromFile:     item.Opts.FromFile,
+		Children:     children,
+
+		BuildConstraint: escape(item.BuildConstraint),
+		File:            escape(item.File),
+		Line:            item.Line,
+		SourceLink:      sourceLink,
+	}
+}
@@ -0,0 +1,126 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// quoteYAML wraps s into YAML double-quoted scalar.
+func quoteYAML(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// escapeCompose escapes docker-compose variable interpolation in s.
+func escapeCompose(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// composeDefault returns docker-compose value of variable with default value:
+// ${name:-value} interpolation, compose can't escape closing brace in it,
+// so value with closing brace is returned as a literal value instead.
+func composeDefault(name, value string) string {
+	value = escapeCompose(value)
+	if strings.Contains(value, "}") {
+		return value
+	}
+	return "${" + name + ":-" + value + "}"
+}
+
+// quoteDocker wraps s into Dockerfile double-quoted string,
+// it escapes variable substitution too.
+func quoteDocker(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// escapeDotenv escapes s for double-quoted dotenv value.
+func escapeDotenv(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// escapeShell escapes s for POSIX shell double-quoted string.
+// Systemd EnvironmentFile uses the same escaping rules for double-quoted values.
+func escapeShell(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return r.Replace(s)
+}
+
+// escapeRoff escapes roff special characters in s, so it's rendered
+// as a plain text: backslashes and control characters at line start.
+func escapeRoff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	asciidocAttrRef   = regexp.MustCompile(`\{(\w[\w-]*)\}`)
+	asciidocBlockLine = regexp.MustCompile(`^\s*([=*\-.\[/|<>+:']|\d+\.)`)
+)
+
+// escapeAsciiDoc escapes s for AsciiDoc paragraph text: it prevents
+// attribute references, block and list markers at line start,
+// and description list terms.
+func escapeAsciiDoc(s string) string {
+	s = asciidocAttrRef.ReplaceAllString(s, `\{$1}`)
+	s = strings.ReplaceAll(s, "::", ":{empty}:")
+	s = strings.ReplaceAll(s, ";;", ";{empty};")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if asciidocBlockLine.MatchString(line) {
+			lines[i] = "{empty}" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeRST escapes reStructuredText inline markup characters in s.
+func escapeRST(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "*", `\*`, "`", "\\`", "_", `\_`, "|", `\|`)
+	return r.Replace(s)
+}
+
+// literalRST wraps s into reStructuredText inline literal, values which
+// contain backticks are written as :literal: role with escaping.
+func literalRST(s string) string {
+	if strings.Contains(s, "`") || strings.TrimSpace(s) != s {
+		return ":literal:`" + escapeRST(s) + "`"
+	}
+	return "``" + s + "``"
+}
+
+// literalAsciiDoc wraps s into AsciiDoc literal monospace text, values which
+// can't be written as `+s+` are written as inline passthrough macro.
+func literalAsciiDoc(s string) string {
+	if strings.Contains(s, "+") || strings.TrimSpace(s) != s {
+		return "`pass:c[" + strings.ReplaceAll(s, "]", `\]`) + "]`"
+	}
+	return "`+" + s + "+`"
+}
+
+// literal wraps s into inline literal markup of format config,
+// s is returned as is if the format has no literal markup.
+func literal(cfg TemplateItemConfig, s string) string {
+	switch cfg.Literal {
+	case "rst":
+		return literalRST(s)
+	case "asciidoc":
+		return literalAsciiDoc(s)
+	default:
+		return s
+	}
+}
+
+// underline returns a line of c characters with the same width as s,
+// it's used for reStructuredText section titles.
+func underline(c, s string) string {
+	return strings.Repeat(c, utf8.RuneCountInString(s))
+}
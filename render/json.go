@@ -0,0 +1,57 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/g4s8/envdoc/types"
+)
+
+// ReadJSON reads documentation scopes from output of JSON format,
+// so previously generated documentation can be used as a spec.
+func ReadJSON(r io.Reader) ([]*types.EnvScope, error) {
+	var sections []TemplateSection
+	if err := json.NewDecoder(r).Decode(&sections); err != nil {
+		return nil, fmt.Errorf("decode JSON: %w", err)
+	}
+	scopes := make([]*types.EnvScope, len(sections))
+	for i, s := range sections {
+		scope := &types.EnvScope{
+			Name: s.Name,
+			Doc:  s.Doc,
+			Vars: make([]*types.EnvDocItem, len(s.Items)),
+		}
+		for j, item := range s.Items {
+			scope.Vars[j] = docItemFromJSON(item)
+		}
+		scopes[i] = scope
+	}
+	return scopes, nil
+}
+
+func docItemFromJSON(item TemplateItem) *types.EnvDocItem {
+	res := &types.EnvDocItem{
+		Name: item.EnvName,
+		Doc:  item.Doc,
+		Type: item.Type,
+		Opts: types.EnvVarOptions{
+			Separator: item.EnvSeparator,
+			Required:  item.Required,
+			Expand:    item.Expand,
+			NonEmpty:  item.NonEmpty,
+			FromFile:  item.FromFile,
+			Default:   item.EnvDefault,
+		},
+		BuildConstraint: item.BuildConstraint,
+		File:            item.File,
+		Line:            item.Line,
+	}
+	if len(item.Children) > 0 {
+		res.Children = make([]*types.EnvDocItem, len(item.Children))
+		for i, child := range item.Children {
+			res.Children[i] = docItemFromJSON(child)
+		}
+	}
+	return res
+}
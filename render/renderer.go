@@ -1,124 +1,169 @@
 package render
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 
 	"github.com/g4s8/envdoc/types"
 )
 
-type Renderer struct {
-	format   types.OutFormat
-	noStyles bool
-}
+// DefaultComposeService is a service name used by compose format by default.
+const DefaultComposeService = "app"
+
+type RendererOption func(*Renderer)
 
-func NewRenderer(format types.OutFormat, noStyles bool) *Renderer {
-	return &Renderer{
-		format:   format,
-		noStyles: noStyles,
+// WithComposeService sets service name for compose format.
+func WithComposeService(name string) RendererOption {
+	return func(r *Renderer) {
+		r.composeService = name
 	}
 }
 
-func (r *Renderer) Render(scopes []*types.EnvScope, out io.Writer) error {
-	cfg, ok := configs[r.format]
-	if !ok {
-		return fmt.Errorf("unknown format: %q", r.format)
+// WithSectionOnly renders only the ENVIRONMENT section for man format
+// without page header, or page body for HTML format.
+func WithSectionOnly(sectionOnly bool) RendererOption {
+	return func(r *Renderer) {
+		r.sectionOnly = sectionOnly
 	}
+}
 
-	c := newRenderContext(scopes, cfg, r.noStyles)
-	f := templateRenderer(cfg.tmpl)
-
-	if err := f(c, out); err != nil {
-		return fmt.Errorf("render: %w", err)
+// WithTemplateFile renders output using custom template file
+// instead of built-in template of the format.
+func WithTemplateFile(path string) RendererOption {
+	return func(r *Renderer) {
+		r.templateFile = path
 	}
-	return nil
 }
 
-type renderSection struct {
-	Name  string       `json:"name,omitempty"`
-	Doc   string       `json:"doc,omitempty"`
-	Items []renderItem `json:"items,omitempty"`
+// WithInteractive renders HTML page with a search box and filters
+// of variables, it's used by the preview server. Text values of the page
+// are HTML-escaped, since it's served over HTTP.
+func WithInteractive(interactive bool) RendererOption {
+	return func(r *Renderer) {
+		r.interactive = interactive
+	}
 }
 
-type renderItem struct {
-	EnvName      string `json:"env_name,omitempty"`
-	Doc          string `json:"doc,omitempty"`
-	EnvDefault   string `json:"env_default,omitempty"`
-	EnvSeparator string `json:"env_separator,omitempty"`
+// WithSourceLink sets a function which returns a link to the variable
+// declaration by source file path and line number.
+func WithSourceLink(link func(file string, line int) string) RendererOption {
+	return func(r *Renderer) {
+		r.sourceLink = link
+	}
+}
 
-	Required bool `json:"required,omitempty"`
-	Expand   bool `json:"expand,omitempty"`
-	NonEmpty bool `json:"non_empty,omitempty"`
-	FromFile bool `json:"from_file,omitempty"`
+// WithGoPackage sets package name of go format, it's the package
+// of the first documented type by default.
+func WithGoPackage(name string) RendererOption {
+	return func(r *Renderer) {
+		r.goPackage = name
+	}
+}
 
-	Children []renderItem `json:"children,omitempty"`
-	Indent   int          `json:"-"`
+type Renderer struct {
+	format         types.OutFormat
+	noStyles       bool
+	composeService string
+	sectionOnly    bool
+	templateFile   string
+	interactive    bool
+	sourceLink     sourceLinkFunc
+	goPackage      string
 }
 
-func (i renderItem) IndentChildren(indentInc int) []renderItem {
-	indent := i.Indent + indentInc
-	res := make([]renderItem, len(i.Children))
-	for j, child := range i.Children {
-		child.Indent = indent
-		res[j] = child
+func NewRenderer(format types.OutFormat, noStyles bool, opts ...RendererOption) *Renderer {
+	r := &Renderer{
+		format:         format,
+		noStyles:       noStyles,
+		composeService: DefaultComposeService,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
-	return res
+	return r
 }
 
-type renderContext struct {
-	Title    string
-	Sections []renderSection
-	Styles   bool
-	Config   renderConfig
-}
+func (r *Renderer) Render(scopes []*types.EnvScope, out io.Writer) error {
+	cfg, ok := configs[r.format]
+	if !ok {
+		return fmt.Errorf("unknown format: %q", r.format)
+	}
 
-func newRenderContext(scopes []*types.EnvScope, cfg renderConfig, noStyles bool) renderContext {
-	res := renderContext{
-		Sections: make([]renderSection, len(scopes)),
-		Styles:   !noStyles,
-		Config:   cfg,
-	}
-	res.Title = "Environment Variables"
-	for i, scope := range scopes {
-		section := renderSection{
-			Name:  scope.Name,
-			Doc:   scope.Doc,
-			Items: make([]renderItem, len(scope.Vars)),
+	if r.interactive && r.format == types.OutFormatHTML {
+		cfg.escape = html.EscapeString
+	}
+	c := newTemplateData(scopes, cfg, r.noStyles, r.sourceLink)
+	c.Service = r.composeService
+	c.SectionOnly = r.sectionOnly
+	c.Interactive = r.interactive
+	c.Package = r.goPackage
+	for _, s := range scopes {
+		if c.Package != "" {
+			break
 		}
-		for j, item := range scope.Vars {
-			item := newRenderItem(item)
-			item.Indent = 1
-			section.Items[j] = item
+		c.Package = s.Pkg
+	}
+	if r.format == types.OutFormatGo {
+		if err := checkGoOutput(c); err != nil {
+			return err
+		}
+	}
+	tmpl := cfg.tmpl
+	if r.templateFile != "" {
+		t, err := newTmplFile(r.templateFile)
+		if err != nil {
+			return fmt.Errorf("load template: %w", err)
+		}
+		tmpl = t
+	}
+	f := templateRenderer(tmpl)
+
+	if cfg.format == nil {
+		if err := f(c, out); err != nil {
+			return fmt.Errorf("render: %w", err)
 		}
-		res.Sections[i] = section
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := f(c, &buf); err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	data, err := cfg.format(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format output: %w", err)
 	}
-	return res
+	if _, err := out.Write(data); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
 }
 
-func newRenderItem(item *types.EnvDocItem) renderItem {
-	children := make([]renderItem, len(item.Children))
-	for i, child := range item.Children {
-		children[i] = newRenderItem(child)
-	}
-	return renderItem{
-		EnvName:      item.Name,
-		Doc:          item.Doc,
-		EnvDefault:   item.Opts.Default,
-		EnvSeparator: item.Opts.Separator,
-		Required:     item.Opts.Required,
-		Expand:       item.Opts.Expand,
-		NonEmpty:     item.Opts.NonEmpty,
-		FromFile:     item.Opts.FromFile,
-		Children:     children,
+// checkGoOutput checks that go format output can be compiled:
+// package name is known and constant names of variables are unique.
+func checkGoOutput(c TemplateData) error {
+	if c.Package == "" {
+		return errors.New("package name of go format is unknown, set it explicitly")
 	}
+	names := make(map[string]string)
+	for _, item := range uniqueVars(c.Sections) {
+		name := goName(item.EnvName)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("variables %s and %s have the same Go name %s", other, item.EnvName, name)
+		}
+		names[name] = item.EnvName
+	}
+	return nil
 }
 
 type template interface {
 	Execute(wr io.Writer, data any) error
 }
 
-func templateRenderer(t template) func(renderContext, io.Writer) error {
-	return func(c renderContext, out io.Writer) error {
+func templateRenderer(t template) func(TemplateData, io.Writer) error {
+	return func(c TemplateData, out io.Writer) error {
 		if err := t.Execute(out, c); err != nil {
 			return fmt.Errorf("render template: %w", err)
 		}
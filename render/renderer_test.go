@@ -1,6 +1,10 @@
 package render
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -48,3 +52,768 @@ func TestRenderer(t *testing.T) {
 		t.Fatalf("Unexpected output")
 	}
 }
+
+func TestRendererCompose(t *testing.T) {
+	r := NewRenderer(types.OutFormatCompose, false, WithComposeService("web"))
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Doc:  "VAR1 doc",
+					Opts: types.EnvVarOptions{
+						Required: true,
+					},
+				},
+				{
+					Name: "VAR2",
+					Opts: types.EnvVarOptions{
+						Default: `$x "y"`,
+					},
+				},
+				{
+					Name: "VAR3",
+					Opts: types.EnvVarOptions{
+						Default: `{"a":"${b}"}`,
+					},
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := r.Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect := `# Environment Variables
+services:
+  web:
+    environment:
+      # scope1
+
+      # VAR1 doc
+      # (required)
+      VAR1: "${VAR1:?required}"
+
+      # (default: '$x "y"')
+      VAR2: "${VAR2:-$$x \"y\"}"
+
+      # (default: '{"a":"${b}"}')
+      VAR3: "{\"a\":\"$${b}\"}"
+`
+	if actual := sb.String(); actual != expect {
+		t.Logf("Expected:\n%s", expect)
+		t.Logf("Got:\n%s", actual)
+		t.Fatalf("Unexpected output")
+	}
+}
+
+func TestRendererDockerfile(t *testing.T) {
+	r := NewRenderer(types.OutFormatDocker, false)
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Opts: types.EnvVarOptions{
+						Required: true,
+					},
+				},
+				{
+					Name: "VAR2",
+					Opts: types.EnvVarOptions{
+						Default: `$x`,
+					},
+				},
+				{
+					Name: "VAR3",
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := r.Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect := `# Environment Variables
+
+# scope1
+
+# (required)
+ARG VAR1
+ENV VAR1=${VAR1:?required}
+
+# (default: '$x')
+ENV VAR2="\$x"
+
+ARG VAR3
+ENV VAR3=${VAR3}
+`
+	if actual := sb.String(); actual != expect {
+		t.Logf("Expected:\n%s", expect)
+		t.Logf("Got:\n%s", actual)
+		t.Fatalf("Unexpected output")
+	}
+}
+
+func TestRendererShell(t *testing.T) {
+	r := NewRenderer(types.OutFormatShell, false)
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Opts: types.EnvVarOptions{
+						Required: true,
+					},
+				},
+				{
+					Name: "VAR2",
+					Opts: types.EnvVarOptions{
+						Required: true,
+						NonEmpty: true,
+						Default:  `"$x"`,
+					},
+				},
+				{
+					Name: "VAR3",
+					Opts: types.EnvVarOptions{
+						Required: true,
+						NonEmpty: true,
+					},
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := r.Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect := `#!/bin/sh
+# Environment Variables
+
+envdoc_missing=""
+[ -n "${VAR1+x}" ] || envdoc_missing="$envdoc_missing VAR1"
+[ -n "${VAR3:-}" ] || envdoc_missing="$envdoc_missing VAR3"
+if [ -n "$envdoc_missing" ]; then
+  echo "missing required environment variables:$envdoc_missing" >&2
+  exit 1
+fi
+
+## scope1
+#
+# (required)
+export VAR1
+
+# (required, non-empty, default: '"$x"')
+export VAR2="${VAR2:-\"\$x\"}"
+
+# (required, non-empty)
+export VAR3
+`
+	if actual := sb.String(); actual != expect {
+		t.Logf("Expected:\n%s", expect)
+		t.Logf("Got:\n%s", actual)
+		t.Fatalf("Unexpected output")
+	}
+}
+
+func TestRendererMan(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Doc:  ".scope1 doc",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Doc:  "VAR1 doc",
+					Opts: types.EnvVarOptions{
+						Required: true,
+						Default:  `C:\foo`,
+					},
+				},
+				{
+					Doc: "Group",
+					Children: []*types.EnvDocItem{
+						{Name: "VAR2"},
+					},
+				},
+			},
+		},
+	}
+	section := `.SH ENVIRONMENT
+.SS scope1
+.PP
+\&.scope1 doc
+.TP
+.B VAR1
+VAR1 doc
+\fI(required, default: C:\efoo)\fR
+.PP
+Group
+.RS
+.TP
+.B VAR2
+.RE
+`
+	t.Run("page", func(t *testing.T) {
+		var sb strings.Builder
+		if err := NewRenderer(types.OutFormatMan, false).Render(scopes, &sb); err != nil {
+			t.Fatalf("Failed to render: %s", err)
+		}
+		expect := ".TH ENVIRONMENT 7\n.SH NAME\nenvironment \\- Environment Variables\n" + section
+		if actual := sb.String(); actual != expect {
+			t.Logf("Expected:\n%s", expect)
+			t.Logf("Got:\n%s", actual)
+			t.Fatalf("Unexpected output")
+		}
+	})
+	t.Run("section only", func(t *testing.T) {
+		var sb strings.Builder
+		if err := NewRenderer(types.OutFormatMan, false, WithSectionOnly(true)).Render(scopes, &sb); err != nil {
+			t.Fatalf("Failed to render: %s", err)
+		}
+		if actual := sb.String(); actual != section {
+			t.Logf("Expected:\n%s", section)
+			t.Logf("Got:\n%s", actual)
+			t.Fatalf("Unexpected output")
+		}
+	})
+}
+
+func TestRendererHTMLSectionOnly(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Doc:  "VAR1 doc",
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := NewRenderer(types.OutFormatHTML, false, WithSectionOnly(true)).Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	actual := sb.String()
+	if strings.Contains(actual, "<html") || strings.Contains(actual, "<style>") {
+		t.Fatalf("Unexpected page wrapper in output:\n%s", actual)
+	}
+	if !strings.HasPrefix(actual, "<h1>Environment Variables</h1>") {
+		t.Fatalf("Unexpected output:\n%s", actual)
+	}
+	if !strings.Contains(actual, "<code>VAR1</code> - VAR1 doc") {
+		t.Fatalf("Missing VAR1 in output:\n%s", actual)
+	}
+}
+
+func TestRendererAsciiDoc(t *testing.T) {
+	r := NewRenderer(types.OutFormatAsciiDoc, false)
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Doc:  "scope1 {doc}",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Doc:  "VAR1 doc",
+					Opts: types.EnvVarOptions{
+						Required: true,
+					},
+				},
+				{
+					Doc: "Group",
+					Children: []*types.EnvDocItem{
+						{
+							Name: "VAR2",
+							Opts: types.EnvVarOptions{
+								Default: "foo",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := r.Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect := `= Environment Variables
+
+== scope1
+
+scope1 \{doc}
+
+` + "`+VAR1+`" + `:: VAR1 doc
+_(*required*)_
+Group::
+` + "`+VAR2+`" + `::: _(default: ` + "`+foo+`" + `)_
+
+`
+	if actual := sb.String(); actual != expect {
+		t.Logf("Expected:\n%s", expect)
+		t.Logf("Got:\n%s", actual)
+		t.Fatalf("Unexpected output")
+	}
+}
+
+func TestRendererRST(t *testing.T) {
+	r := NewRenderer(types.OutFormatRST, false)
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Doc:  "scope1 *doc*",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Doc:  "VAR1 doc",
+					Opts: types.EnvVarOptions{
+						Required: true,
+					},
+				},
+				{
+					Doc: "Group",
+					Children: []*types.EnvDocItem{
+						{
+							Name: "VAR2",
+							Opts: types.EnvVarOptions{
+								Default: "foo",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := r.Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect := `Environment Variables
+=====================
+
+scope1
+------
+
+scope1 \*doc\*
+
+` + "``VAR1``" + `
+   VAR1 doc
+   (**required**)
+
+Group
+   ` + "``VAR2``" + `
+      (default: ` + "``foo``" + `)
+
+`
+	if actual := sb.String(); actual != expect {
+		t.Logf("Expected:\n%s", expect)
+		t.Logf("Got:\n%s", actual)
+		t.Fatalf("Unexpected output")
+	}
+}
+
+func TestRendererMarkupEscape(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "{db}_*scope*",
+			Vars: []*types.EnvDocItem{
+				{Name: "PLAIN", Opts: types.EnvVarOptions{Default: "foo"}},
+				{Name: "QUOTE", Opts: types.EnvVarOptions{Default: "a`b"}},
+				{Name: "PLUS", Opts: types.EnvVarOptions{Default: "a+ b]"}},
+				{Name: "SEP", Opts: types.EnvVarOptions{Separator: "+"}},
+			},
+		},
+	}
+	for _, c := range []struct {
+		format types.OutFormat
+		expect []string
+	}{
+		{
+			format: types.OutFormatRST,
+			expect: []string{
+				"{db}\\_\\*scope\\*\n---------------\n",
+				"``PLAIN``\n   (default: ``foo``)",
+				"``QUOTE``\n   (default: :literal:`a\\`b`)",
+				"``PLUS``\n   (default: ``a+ b]``)",
+				"``SEP``\n   (separated by ``+``)",
+			},
+		},
+		{
+			format: types.OutFormatAsciiDoc,
+			expect: []string{
+				"== \\{db}_*scope*\n",
+				"`+PLAIN+`:: _(default: `+foo+`)_",
+				"`+QUOTE+`:: _(default: `+a`b+`)_",
+				"`+PLUS+`:: _(default: `pass:c[a+ b\\]]`)_",
+				"`+SEP+`:: _(separated by `pass:c[+]`)_",
+			},
+		},
+	} {
+		t.Run(string(c.format), func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewRenderer(c.format, false).Render(scopes, &sb); err != nil {
+				t.Fatalf("Failed to render: %s", err)
+			}
+			for _, expect := range c.expect {
+				if !strings.Contains(sb.String(), expect) {
+					t.Errorf("Expected %q in output:\n%s", expect, sb.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRendererTemplateFile(t *testing.T) {
+	tmpl := filepath.Join(t.TempDir(), "custom.tmpl")
+	data := `{{- range .Sections }}{{ .Name }}:
+{{ range .Items }}{{ .EnvName }}{{ template "item.options" (list . $.Config.Item " [%s]") }}
+{{ end }}{{ end -}}`
+	if err := os.WriteFile(tmpl, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write template: %s", err)
+	}
+	scopes := []*types.EnvScope{
+		{
+			Name: "scope1",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "VAR1",
+					Opts: types.EnvVarOptions{
+						Required: true,
+					},
+				},
+				{
+					Name: "VAR2",
+				},
+			},
+		},
+	}
+	t.Run("render", func(t *testing.T) {
+		r := NewRenderer(types.OutFormatMarkdown, false, WithTemplateFile(tmpl))
+		var sb strings.Builder
+		if err := r.Render(scopes, &sb); err != nil {
+			t.Fatalf("Failed to render: %s", err)
+		}
+		expect := "scope1:\nVAR1 [**required**]\nVAR2\n"
+		if actual := sb.String(); actual != expect {
+			t.Logf("Expected:\n%s", expect)
+			t.Logf("Got:\n%s", actual)
+			t.Fatalf("Unexpected output")
+		}
+	})
+	t.Run("missing file", func(t *testing.T) {
+		r := NewRenderer(types.OutFormatMarkdown, false, WithTemplateFile(tmpl+".missing"))
+		var sb strings.Builder
+		if err := r.Render(scopes, &sb); err == nil {
+			t.Fatal("Expected error for missing template file")
+		}
+	})
+}
+
+func TestRendererBuildConstraint(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{
+					Name:            "CGROUP",
+					Doc:             "Cgroup path.",
+					Opts:            types.EnvVarOptions{Required: true},
+					BuildConstraint: "linux && !android",
+				},
+			},
+		},
+	}
+	for _, tc := range []struct {
+		format types.OutFormat
+		expect string
+	}{
+		{types.OutFormatMarkdown, "- `CGROUP` (**required**, build: `linux && !android`) - Cgroup path."},
+		{types.OutFormatHTML, "(<strong>required</strong>, build: <code>linux && !android</code>)"},
+		{types.OutFormatEnv, "# (required, build: 'linux && !android')"},
+		{types.OutFormatJSON, `"build_constraint": "linux \u0026\u0026 !android"`},
+	} {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewRenderer(tc.format, true).Render(scopes, &sb); err != nil {
+				t.Fatalf("Failed to render: %s", err)
+			}
+			if !strings.Contains(sb.String(), tc.expect) {
+				t.Fatalf("Expected %q in output:\n%s", tc.expect, sb.String())
+			}
+		})
+	}
+
+}
+
+func TestRendererInteractive(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "DB_PASSWORD",
+					Doc:  "Database password.",
+					Opts: types.EnvVarOptions{Required: true, Default: "secret"},
+					File: "config.go",
+					Line: 12,
+				},
+			},
+		},
+	}
+	link := func(file string, line int) string {
+		return fmt.Sprintf("/source/%s#L%d", file, line)
+	}
+	var sb strings.Builder
+	if err := NewRenderer(types.OutFormatHTML, false, WithSourceLink(link)).Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	if actual := sb.String(); strings.Contains(actual, "data-name") || strings.Contains(actual, "<script>") {
+		t.Fatalf("Unexpected interactive elements in output:\n%s", actual)
+	}
+	if !strings.Contains(sb.String(), `(defined at <a class="source" href="/source/config.go#L12">config.go:12</a>)`) {
+		t.Fatalf("Missing source link in output:\n%s", sb.String())
+	}
+
+	sb.Reset()
+	if err := NewRenderer(types.OutFormatHTML, false, WithInteractive(true)).Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	actual := sb.String()
+	for _, expect := range []string{
+		`<input type="search" id="search"`,
+		`<li data-name="DB_PASSWORD" data-required data-default data-secret>`,
+		`<h2 id="Config">Config</h2>`,
+		"<script>",
+	} {
+		if !strings.Contains(actual, expect) {
+			t.Errorf("Expected %q in output:\n%s", expect, actual)
+		}
+	}
+	if strings.Contains(actual, `class="source"`) {
+		t.Errorf("Unexpected source link without link function:\n%s", actual)
+	}
+
+	// served page escapes text values of sources
+	scopes[0].Vars[0].Doc = `Password <script>alert("x")</script>.`
+	sb.Reset()
+	if err := NewRenderer(types.OutFormatHTML, false, WithInteractive(true), WithSourceLink(link)).Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	actual = sb.String()
+	if strings.Contains(actual, `<script>alert`) {
+		t.Errorf("Unescaped doc in interactive output:\n%s", actual)
+	}
+	if !strings.Contains(actual, `Password &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;.`) {
+		t.Errorf("Expected escaped doc in output:\n%s", actual)
+	}
+}
+
+func TestTemplateItemSecret(t *testing.T) {
+	for name, expect := range map[string]bool{
+		"DB_PASSWORD":   true,
+		"API_KEY":       true,
+		"GITHUB_TOKEN":  true,
+		"client_secret": true,
+		"KEYSPACE":      false,
+		"PORT":          false,
+	} {
+		if actual := (TemplateItem{EnvName: name}).Secret(); actual != expect {
+			t.Errorf("%s: expected secret=%t, got %t", name, expect, actual)
+		}
+	}
+}
+
+func TestRendererSourcePosition(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{Name: "PORT", Doc: "Port to listen on.", File: "config.go", Line: 12},
+			},
+		},
+	}
+	link := func(file string, line int) string {
+		return fmt.Sprintf("https://git.example.com/repo/blob/main/%s#L%d", file, line)
+	}
+	for _, tc := range []struct {
+		format types.OutFormat
+		expect string
+	}{
+		{
+			types.OutFormatMarkdown,
+			"- `PORT` - Port to listen on. (defined at [config.go:12](https://git.example.com/repo/blob/main/config.go#L12))",
+		},
+		{
+			types.OutFormatHTML,
+			`(defined at <a class="source" href="https://git.example.com/repo/blob/main/config.go#L12">config.go:12</a>)`,
+		},
+		{types.OutFormatJSON, `"file": "config.go",` + "\n" + `        "line": 12,`},
+	} {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewRenderer(tc.format, true, WithSourceLink(link)).Render(scopes, &sb); err != nil {
+				t.Fatalf("Failed to render: %s", err)
+			}
+			if !strings.Contains(sb.String(), tc.expect) {
+				t.Fatalf("Expected %q in output:\n%s", tc.expect, sb.String())
+			}
+		})
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Doc:  "Config doc.",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "HOSTS",
+					Doc:  "Hosts doc.",
+					Type: "[]string",
+					Opts: types.EnvVarOptions{Separator: ";", Required: true, Default: "a;b"},
+					File: "config.go",
+					Line: 5,
+				},
+				{
+					Doc: "Group doc.",
+					Children: []*types.EnvDocItem{
+						{Name: "DB_PASSWORD_FILE", Type: "string", Opts: types.EnvVarOptions{FromFile: true, NonEmpty: true}},
+					},
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := NewRenderer(types.OutFormatJSON, true).Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	actual, err := ReadJSON(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("Failed to read JSON: %s", err)
+	}
+	if !reflect.DeepEqual(scopes, actual) {
+		t.Fatalf("Unexpected scopes:\n%s", sb.String())
+	}
+	// raw positions are rendered without source link template
+	if !strings.Contains(sb.String(), `"line": 5`) || strings.Contains(sb.String(), `"source_link"`) {
+		t.Fatalf("Unexpected source position in output:\n%s", sb.String())
+	}
+
+	if _, err := ReadJSON(strings.NewReader("{")); err == nil {
+		t.Fatal("Expected error for invalid JSON")
+	}
+}
+
+func TestRendererGo(t *testing.T) {
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Pkg:  "config",
+			Vars: []*types.EnvDocItem{
+				{
+					Name: "DB_HOST",
+					Doc:  "Database \"host\".",
+					Type: "string",
+					Opts: types.EnvVarOptions{Required: true},
+				},
+				{
+					Doc: "Group",
+					Children: []*types.EnvDocItem{
+						{Name: "HTTP_PORT", Type: "int", Opts: types.EnvVarOptions{Default: "80"}},
+						{Name: "DB_HOST", Type: "string"},
+					},
+				},
+			},
+		},
+	}
+	var sb strings.Builder
+	if err := NewRenderer(types.OutFormatGo, false).Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect := `// Code generated by envdoc. DO NOT EDIT.
+
+package config
+
+// Environment variable names.
+const (
+	EnvDBHost   = "DB_HOST"
+	EnvHTTPPort = "HTTP_PORT"
+)
+
+// EnvVarDoc is a documentation of environment variable.
+type EnvVarDoc struct {
+	// Name of the variable.
+	Name string
+	// Doc is a documentation text of the variable.
+	Doc string
+	// Default is a default value of the variable.
+	Default string
+	// Required is true if the variable must be set.
+	Required bool
+	// Type is a Go type name of the variable field.
+	Type string
+}
+
+// EnvDocs is a documentation of all environment variables.
+var EnvDocs = []EnvVarDoc{
+	{
+		Name:     EnvDBHost,
+		Doc:      "Database \"host\".",
+		Default:  "",
+		Required: true,
+		Type:     "string",
+	},
+	{
+		Name:     EnvHTTPPort,
+		Doc:      "",
+		Default:  "80",
+		Required: false,
+		Type:     "int",
+	},
+}
+`
+	if actual := sb.String(); actual != expect {
+		t.Logf("Expected:\n%s", expect)
+		t.Logf("Got:\n%s", actual)
+		t.Fatalf("Unexpected output")
+	}
+
+	sb.Reset()
+	if err := NewRenderer(types.OutFormatGo, false, WithGoPackage("envs")).Render(scopes, &sb); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	if !strings.Contains(sb.String(), "\npackage envs\n") {
+		t.Fatalf("Unexpected package:\n%s", sb.String())
+	}
+
+	// package name is required
+	err := NewRenderer(types.OutFormatGo, false).Render(nil, &sb)
+	if err == nil || !strings.Contains(err.Error(), "package name") {
+		t.Fatalf("Expected unknown package error, got: %v", err)
+	}
+
+	// constant names must be unique
+	scopes[0].Vars = append(scopes[0].Vars, &types.EnvDocItem{Name: "DB__HOST", Type: "string"})
+	err = NewRenderer(types.OutFormatGo, false).Render(scopes, &sb)
+	if err == nil || err.Error() != "variables DB_HOST and DB__HOST have the same Go name EnvDBHost" {
+		t.Fatalf("Expected duplicate name error, got: %v", err)
+	}
+}
+
+func TestTemplateConfig(t *testing.T) {
+	// template data is built and compared by custom template callers
+	cfg := TemplateConfig{Item: configs[types.OutFormatMarkdown].item}
+	if cfg != configs[types.OutFormatMarkdown].templateConfig() {
+		t.Fatalf("Unexpected template config: %+v", cfg)
+	}
+	if cfg == (TemplateConfig{}) {
+		t.Fatalf("Empty template config of markdown format")
+	}
+}
@@ -3,10 +3,15 @@ package render
 import (
 	"embed"
 	"encoding/json"
+	"fmt"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	texttmpl "text/template"
+
+	"github.com/g4s8/envdoc/utils"
 )
 
 //go:embed templ
@@ -36,6 +41,46 @@ var tplFuncs = map[string]any{
 		a, err := json.MarshalIndent(v, "", "  ")
 		return string(a), err
 	},
+	"quoteYAML":      quoteYAML,
+	"escapeCompose":  escapeCompose,
+	"composeDefault": composeDefault,
+	"quoteDocker":    quoteDocker,
+	"escapeDotenv":   escapeDotenv,
+	"escapeShell":    escapeShell,
+	"escapeRoff":     escapeRoff,
+	"escapeAsciiDoc": escapeAsciiDoc,
+	"escapeRST":      escapeRST,
+	"literal":        literal,
+	"underline":      underline,
+	"vars":           uniqueVars,
+	"goName":         goName,
+	"goQuote":        strconv.Quote,
+}
+
+// uniqueVars returns named variables of sections including nested ones,
+// variables with the same name are returned once.
+func uniqueVars(sections []TemplateSection) []TemplateItem {
+	var res []TemplateItem
+	seen := make(map[string]bool)
+	var walk func(items []TemplateItem)
+	walk = func(items []TemplateItem) {
+		for _, item := range items {
+			if item.EnvName != "" && !seen[item.EnvName] {
+				seen[item.EnvName] = true
+				res = append(res, item)
+			}
+			walk(item.Children)
+		}
+	}
+	for _, s := range sections {
+		walk(s.Items)
+	}
+	return res
+}
+
+// goName returns Go constant name of environment variable, e.g. EnvDBHost for DB_HOST.
+func goName(envName string) string {
+	return "Env" + utils.SnakeToCamel(envName)
 }
 
 const (
@@ -43,6 +88,21 @@ const (
 	tmplHelpers = "helpers.tmpl"
 )
 
+// newTmplFile loads user-supplied template file with
+// template functions and helpers defined.
+func newTmplFile(file string) (*texttmpl.Template, error) {
+	t, err := texttmpl.New(filepath.Base(file)).
+		Funcs(tplFuncs).
+		ParseFS(templatesFS, path.Join(tmplDir, tmplHelpers))
+	if err != nil {
+		return nil, fmt.Errorf("parse helpers: %w", err)
+	}
+	if t, err = t.ParseFiles(file); err != nil {
+		return nil, fmt.Errorf("parse template file: %w", err)
+	}
+	return t, nil
+}
+
 func newTmplText(name string) *texttmpl.Template {
 	return texttmpl.Must(texttmpl.New(name).
 		Funcs(tplFuncs).
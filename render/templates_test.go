@@ -70,4 +70,67 @@ func TestTplFuncs(t *testing.T) {
 			t.Error("sum failed")
 		}
 	})
+	t.Run("quoteYAML", func(t *testing.T) {
+		f := tplFuncs["quoteYAML"].(func(string) string)
+		if res := f(`a "b" \c`); res != `"a \"b\" \\c"` {
+			t.Errorf("quoteYAML failed: %s", res)
+		}
+	})
+	t.Run("escapeCompose", func(t *testing.T) {
+		f := tplFuncs["escapeCompose"].(func(string) string)
+		if res := f("$HOME"); res != "$$HOME" {
+			t.Errorf("escapeCompose failed: %s", res)
+		}
+	})
+	t.Run("composeDefault", func(t *testing.T) {
+		f := tplFuncs["composeDefault"].(func(string, string) string)
+		if res := f("HOME", "$HOME/app"); res != "${HOME:-$$HOME/app}" {
+			t.Errorf("composeDefault failed: %s", res)
+		}
+		if res := f("TPL", "{{.Name}}"); res != "{{.Name}}" {
+			t.Errorf("composeDefault with closing brace failed: %s", res)
+		}
+	})
+	t.Run("quoteDocker", func(t *testing.T) {
+		f := tplFuncs["quoteDocker"].(func(string) string)
+		if res := f(`a "$b"`); res != `"a \"\$b\""` {
+			t.Errorf("quoteDocker failed: %s", res)
+		}
+	})
+	t.Run("escapeDotenv", func(t *testing.T) {
+		f := tplFuncs["escapeDotenv"].(func(string) string)
+		if res := f(`a "b" $c`); res != `a \"b\" $c` {
+			t.Errorf("escapeDotenv failed: %s", res)
+		}
+	})
+	t.Run("escapeShell", func(t *testing.T) {
+		f := tplFuncs["escapeShell"].(func(string) string)
+		if res := f("a \"$b\" `c`"); res != "a \\\"\\$b\\\" \\`c\\`" {
+			t.Errorf("escapeShell failed: %s", res)
+		}
+	})
+	t.Run("escapeRoff", func(t *testing.T) {
+		f := tplFuncs["escapeRoff"].(func(string) string)
+		if res := f(".foo\n'bar\nbaz \\n"); res != "\\&.foo\n\\&'bar\nbaz \\en" {
+			t.Errorf("escapeRoff failed: %q", res)
+		}
+	})
+	t.Run("escapeAsciiDoc", func(t *testing.T) {
+		f := tplFuncs["escapeAsciiDoc"].(func(string) string)
+		if res := f("use {name} here\n* not a list\nterm:: desc"); res != "use \\{name} here\n{empty}* not a list\nterm:{empty}: desc" {
+			t.Errorf("escapeAsciiDoc failed: %q", res)
+		}
+	})
+	t.Run("escapeRST", func(t *testing.T) {
+		f := tplFuncs["escapeRST"].(func(string) string)
+		if res := f("*a* `b` c_ |d|"); res != "\\*a\\* \\`b\\` c\\_ \\|d\\|" {
+			t.Errorf("escapeRST failed: %q", res)
+		}
+	})
+	t.Run("underline", func(t *testing.T) {
+		f := tplFuncs["underline"].(func(string, string) string)
+		if res := f("=", "Юникод"); res != "======" {
+			t.Errorf("underline failed: %q", res)
+		}
+	})
 }
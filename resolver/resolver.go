@@ -3,6 +3,7 @@ package resolver
 import (
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/g4s8/envdoc/ast"
 	"github.com/g4s8/envdoc/debug"
@@ -19,12 +20,30 @@ func (q typeQualifier) String() string {
 
 type TypeResolver struct {
 	types map[typeQualifier]*ast.TypeSpec
+	log   debug.Logger
 }
 
-func NewTypeResolver() *TypeResolver {
-	return &TypeResolver{
+// Option configures type resolver.
+type Option func(*TypeResolver)
+
+// WithLogger sets a logger of resolved types, messages are discarded by default.
+func WithLogger(log debug.Logger) Option {
+	return func(r *TypeResolver) {
+		if log != nil {
+			r.log = log
+		}
+	}
+}
+
+func NewTypeResolver(opts ...Option) *TypeResolver {
+	r := &TypeResolver{
 		types: make(map[typeQualifier]*ast.TypeSpec),
+		log:   debug.NopLogger(),
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 func (r *TypeResolver) AddTypes(pkg string, types []*ast.TypeSpec) {
@@ -45,13 +64,13 @@ func (r *TypeResolver) Resolve(f *ast.FileSpec, ref *ast.FieldTypeRef) *ast.Type
 	}
 	tq := typeQualifier{pkg: pkg, name: ref.Name}
 	ts := r.types[tq]
-	debug.Logf("# RES: ref=%q tq=%q ts=%q",
+	r.log.Logf("# RES: ref=%q tq=%q ts=%q",
 		ref, tq, ts)
 	return ts
 }
 
-func ResolveAllTypes(files []*ast.FileSpec) *TypeResolver {
-	r := NewTypeResolver()
+func ResolveAllTypes(files []*ast.FileSpec, opts ...Option) *TypeResolver {
+	r := NewTypeResolver(opts...)
 	for _, f := range files {
 		pkg := f.Pkg
 		r.AddTypes(pkg, f.Types)
@@ -66,3 +85,30 @@ func (r *TypeResolver) Debug(out io.Writer) {
 			k.pkg, k.name, v.Name, v.Export)
 	}
 }
+
+// Cache keeps resolvers of parsed files, so types of the same sources
+// are resolved once if the parser is running multiple times, e.g. for all
+// go:generate directives of a package. It's safe for concurrent use.
+type Cache struct {
+	mux     sync.Mutex
+	entries map[string]*TypeResolver
+}
+
+// NewCache creates empty resolver cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]*TypeResolver)}
+}
+
+// ResolveAllTypes returns cached resolver of files identified by key,
+// or resolves types of files, see ast.Parser.CacheKey. Options are applied
+// to new resolvers only.
+func (c *Cache) ResolveAllTypes(key string, files []*ast.FileSpec, opts ...Option) *TypeResolver {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if r, ok := c.entries[key]; ok {
+		return r
+	}
+	r := ResolveAllTypes(files, opts...)
+	c.entries[key] = r
+	return r
+}
@@ -80,3 +80,18 @@ func TestResolver(t *testing.T) { //nolint:cyclop
 		t.Errorf("Baz type resolved, but it should not")
 	}
 }
+
+func TestCache(t *testing.T) {
+	files := []*ast.FileSpec{{Pkg: "main", Types: []*ast.TypeSpec{{Name: "Foo"}}}}
+	cache := NewCache()
+	res := cache.ResolveAllTypes("a", files)
+	if res.Resolve(&ast.FileSpec{}, &ast.FieldTypeRef{Pkg: "main", Name: "Foo"}) == nil {
+		t.Fatal("expected Foo type")
+	}
+	if cache.ResolveAllTypes("a", nil) != res {
+		t.Fatal("expected cached resolver of the same key")
+	}
+	if cache.ResolveAllTypes("b", nil) == res {
+		t.Fatal("expected new resolver of another key")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	htmltmpl "html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/g4s8/envdoc/ast"
+	"github.com/g4s8/envdoc/debug"
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/types"
+)
+
+// DefaultServeAddr is a default address of preview server.
+const DefaultServeAddr = "localhost:8080"
+
+// serveCommand starts local HTTP server which renders HTML documentation
+// on every request, it returns exit code when ctx is done.
+func serveCommand(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	var c Config
+	f := flag.NewFlagSet("envdoc serve", flag.ContinueOnError)
+	f.SetOutput(stderr)
+	var addr string
+	f.StringVar(&addr, "addr", DefaultServeAddr, "Address to listen on")
+	if err := c.parseFlags(f, args); err != nil {
+		return 2
+	}
+	cfg, err := c.commandConfig(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load config: %v\n", err)
+		return 2
+	}
+	cfg.OutFile = ""
+	cfg.Outputs = nil
+	cfg.OutFormat = types.OutFormatHTML
+	if cfg.Debug {
+		debug.Config.Enabled = true
+		cfg.fprint(stderr)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "Invalid config: %v\n", err)
+		return 2
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to listen: %v\n", err)
+		return 1
+	}
+	srv := &http.Server{
+		Handler:           newServeHandler(cfg, stderr),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx) //nolint:errcheck
+	}()
+	fmt.Fprintf(stdout, "Serving documentation of %s at http://%s/, press Ctrl+C to stop\n", cfg.Dir, l.Addr())
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "Failed to serve: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// docServer renders documentation page and source files
+// of documented variables.
+type docServer struct {
+	cfg   Config
+	cache *ast.DiskCache
+	log   io.Writer
+}
+
+func newServeHandler(cfg Config, log io.Writer) http.Handler {
+	s := &docServer{cfg: cfg, cache: newDiskCache(cfg), log: log}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("GET /source", s.source)
+	return mux
+}
+
+// index parses sources and renders the page, sources are parsed on every
+// request, so the page is always up to date.
+func (s *docServer) index(w http.ResponseWriter, _ *http.Request) {
+	var opts []ast.ParserConfigOption
+	if s.cache != nil {
+		opts = append(opts, ast.WithDiskCache(s.cache))
+	}
+	renderer := render.NewRenderer(types.OutFormatHTML, s.cfg.NoStyles,
+		render.WithInteractive(true),
+		render.WithTemplateFile(s.cfg.TemplateFile),
+		render.WithSourceLink(serveSourceLink))
+	var buf bytes.Buffer
+	gen := newGenerator(s.cfg, renderer, opts...)
+	if err := gen.Generate(s.cfg.Dir, &buf); err != nil {
+		fmt.Fprintf(s.log, "Failed to generate: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to generate: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes()) //nolint:errcheck
+}
+
+func serveSourceLink(file string, line int) string {
+	return "/source?file=" + url.QueryEscape(file) + "#L" + strconv.Itoa(line)
+}
+
+// source renders Go source file with line anchors, only files
+// in the documented directory are served.
+func (s *docServer) source(w http.ResponseWriter, r *http.Request) {
+	file := r.URL.Query().Get("file")
+	if !s.allowedSource(file) {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	type sourceLine struct {
+		N    int
+		Text string
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	page := struct {
+		File  string
+		Lines []sourceLine
+	}{File: file, Lines: make([]sourceLine, len(lines))}
+	for i, text := range lines {
+		page.Lines[i] = sourceLine{N: i + 1, Text: text}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := sourceTemplate.Execute(w, page); err != nil {
+		fmt.Fprintf(s.log, "Failed to render source: %v\n", err)
+	}
+}
+
+func (s *docServer) allowedSource(file string) bool {
+	if !strings.HasSuffix(file, ".go") {
+		return false
+	}
+	root, err := filepath.Abs(s.cfg.Dir)
+	if err != nil {
+		return false
+	}
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+var sourceTemplate = htmltmpl.Must(htmltmpl.New("source").Parse(`<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8">
+    <title>{{ .File }}</title>
+    <style>
+body {
+  font-family: sans-serif;
+  color: #1F2328;
+}
+pre {
+  font-size: 13px;
+  line-height: 1.5;
+}
+pre span {
+  display: block;
+}
+pre span::before {
+  content: attr(data-line);
+  display: inline-block;
+  width: 5em;
+  color: #59636e;
+}
+pre span:target {
+  background-color: #fff8c5;
+}
+    </style>
+  </head>
+  <body>
+    <h1>{{ .File }}</h1>
+    <pre>
+{{- range .Lines }}
+<span id="L{{ .N }}" data-line="{{ .N }}">{{ .Text }}</span>
+{{- end }}
+    </pre>
+  </body>
+</html>
+`))
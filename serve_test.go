@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func TestServeHandler(t *testing.T) {
+	t.Setenv("ENVDOC_CACHE", "off")
+	dir := t.TempDir()
+	src := filepath.Join(dir, "config.go")
+	content := "package a\n\n// Config doc.\ntype Config struct {\n" +
+		"\t// Port doc.\n\tPort int `env:\"PORT,required\"`\n" +
+		"\t// Token doc.\n\tToken string `env:\"API_TOKEN\"`\n}\n"
+	if err := os.WriteFile(src, []byte(content), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	// source files outside of the documented dir are not served
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dir), "other.go"), []byte("package b\n"), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	cfg := Config{
+		Dir:       dir,
+		FileGlob:  "*",
+		TypeGlob:  "*",
+		OutFormat: types.OutFormatHTML,
+		Target:    types.TargetTypeCaarlos0,
+		TagName:   "env",
+	}
+	srv := httptest.NewServer(newServeHandler(cfg, io.Discard))
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	code, page := get("/")
+	testutils.AssertFatal(t, code == http.StatusOK, "unexpected status %d: %s", code, page)
+	for _, expect := range []string{
+		`<input type="search" id="search"`,
+		`<a href="#Config">Config</a>`,
+		`<h2 id="Config">Config</h2>`,
+		`<li data-name="PORT" data-required>`,
+		`<li data-name="API_TOKEN" data-secret>`,
+		`(defined at <a class="source" href="/source?file=` + url.QueryEscape(src) + `#L6">` + src + `:6</a>)`,
+	} {
+		testutils.AssertError(t, strings.Contains(page, expect), "missing %q in page:\n%s", expect, page)
+	}
+
+	// page is rendered on every request
+	content = strings.Replace(content, "API_TOKEN", "AUTH_TOKEN", 1)
+	if err := os.WriteFile(src, []byte(content), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	_, page = get("/")
+	testutils.AssertError(t, strings.Contains(page, `data-name="AUTH_TOKEN"`), "page is not updated:\n%s", page)
+
+	code, page = get("/source?file=" + url.QueryEscape(src))
+	testutils.AssertFatal(t, code == http.StatusOK, "unexpected status %d: %s", code, page)
+	testutils.AssertError(t, strings.Contains(page, `<span id="L6" data-line="6">	Port int `+"`"+`env:&#34;PORT,required&#34;`+"`"+`</span>`),
+		"missing source line in page:\n%s", page)
+
+	for _, file := range []string{filepath.Join(dir, "..", "other.go"), filepath.Join(dir, "missing.go"), "/etc/passwd"} {
+		code, _ = get("/source?file=" + url.QueryEscape(file))
+		testutils.AssertError(t, code == http.StatusNotFound, "unexpected status %d of %s", code, file)
+	}
+}
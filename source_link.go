@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sourceLink returns a function which renders -source-link-template
+// for source file position relative to dir: {file} is replaced with slash-separated
+// path relative to the root of git repository of dir, {line} with line number.
+// Empty dir is the current directory.
+func sourceLink(tmpl, dir string) func(file string, line int) string {
+	root := repoRoot(dir)
+	return func(file string, line int) string {
+		path := file
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		if abs, err := filepath.Abs(file); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil {
+				path = rel
+			}
+		}
+		r := strings.NewReplacer("{file}", filepath.ToSlash(path), "{line}", strconv.Itoa(line))
+		return r.Replace(tmpl)
+	}
+}
+
+// repoRoot returns the nearest parent of dir with .git entry,
+// or dir itself if it's not in a git repository.
+func repoRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for cur := dir; ; {
+		if _, err := os.Stat(filepath.Join(cur, ".git")); err == nil {
+			return cur
+		}
+		parent := filepath.Dir(cur)
+		if parent == cur {
+			return dir
+		}
+		cur = parent
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/g4s8/envdoc/testutils"
+)
+
+func TestSourceLink(t *testing.T) {
+	root := t.TempDir()
+	pkg := filepath.Join(root, "internal", "config")
+	for _, dir := range []string{filepath.Join(root, ".git"), pkg} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	t.Chdir(pkg)
+
+	link := sourceLink("https://git.example.com/repo/blob/main/{file}#L{line}", ".")
+	actual := link("config.go", 12)
+	expect := "https://git.example.com/repo/blob/main/internal/config/config.go#L12"
+	testutils.AssertError(t, actual == expect, "expected link %q, got %q", expect, actual)
+
+	// without repository the path is relative to dir
+	outside := t.TempDir()
+	link = sourceLink("{file}:{line}", outside)
+	actual = link(filepath.Join(outside, "a", "config.go"), 3)
+	testutils.AssertError(t, actual == "a/config.go:3", "unexpected link %q", actual)
+}
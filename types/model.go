@@ -11,27 +11,67 @@ const (
 	OutFormatTxt      OutFormat = "plaintext"
 	OutFormatEnv      OutFormat = "dotenv"
 	OutFormatJSON     OutFormat = "json"
+	OutFormatCompose  OutFormat = "compose"
+	OutFormatDocker   OutFormat = "dockerfile"
+	OutFormatSystemd  OutFormat = "systemd"
+	OutFormatShell    OutFormat = "shell"
+	OutFormatMan      OutFormat = "man"
+	OutFormatAsciiDoc OutFormat = "asciidoc"
+	OutFormatRST      OutFormat = "rst"
+	OutFormatGo       OutFormat = "go"
 )
 
+// OutFormats is a list of all output formats.
+var OutFormats = []OutFormat{
+	OutFormatMarkdown,
+	OutFormatHTML,
+	OutFormatTxt,
+	OutFormatEnv,
+	OutFormatJSON,
+	OutFormatCompose,
+	OutFormatDocker,
+	OutFormatSystemd,
+	OutFormatShell,
+	OutFormatMan,
+	OutFormatAsciiDoc,
+	OutFormatRST,
+	OutFormatGo,
+}
+
 // EnvDocItem is a documentation item for one environment variable.
 type EnvDocItem struct {
 	// Name of the environment variable.
 	Name string
 	// Doc is a documentation text for the environment variable.
 	Doc string
+	// Type is a Go type name of the field, e.g. int, []string or time.Duration.
+	Type string
 	// Opts is a set of options for environment variable parsing.
 	Opts EnvVarOptions
 	// Children is a list of child environment variables.
 	Children []*EnvDocItem
+	// BuildConstraint is a build constraint of the variable declaration,
+	// the variable exists only in builds matching it.
+	BuildConstraint string
+	// File is a path of source file with the variable declaration.
+	File string
+	// Line is a line number of the variable declaration in File.
+	Line int
 }
 
 type EnvScope struct {
 	// Name of the scope.
 	Name string
+	// Pkg is a Go package name of the scope type.
+	Pkg string
 	// Doc is a documentation text for the scope.
 	Doc string
 	// Vars is a list of environment variables.
 	Vars []*EnvDocItem
+	// File is a path of source file with the type declaration.
+	File string
+	// Line is a line number of the type declaration in File.
+	Line int
 }
 
 // EnvVarOptions is a set of options for environment variable parsing.
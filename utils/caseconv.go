@@ -30,3 +30,37 @@ func CamelToSnake(s string) string {
 
 	return result.String()
 }
+
+// initialisms are name parts which are kept upper-case by SnakeToCamel,
+// like Go naming conventions require.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "AWS": true, "CPU": true, "CSS": true, "DB": true,
+	"DNS": true, "DSN": true, "EOF": true, "GCP": true, "GRPC": true, "GUID": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "JWT": true, "LHS": true,
+	"OS": true, "QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true,
+	"SQL": true, "SSH": true, "SSL": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"UI": true, "UID": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true,
+	"XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// SnakeToCamel converts snake case name to camel case Go identifier,
+// e.g. DB_HOST to DBHost. Parts are split by any non-alphanumeric character,
+// initialisms are kept upper-case.
+func SnakeToCamel(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var result strings.Builder
+	result.Grow(len(s))
+	for _, part := range parts {
+		upper := strings.ToUpper(part)
+		if initialisms[upper] {
+			result.WriteString(upper)
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(part)
+		result.WriteRune(unicode.ToUpper(first))
+		result.WriteString(strings.ToLower(part[size:]))
+	}
+	return result.String()
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContext is a number of unchanged lines around changes in unified diff.
+const diffContext = 3
+
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// UnifiedDiff returns unified diff of from and to texts,
+// it returns empty string if texts are equal.
+func UnifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	lines := diffLines(from, to)
+
+	// fromPos and toPos are numbers of lines before i-th diff line
+	fromPos := make([]int, len(lines)+1)
+	toPos := make([]int, len(lines)+1)
+	for i, l := range lines {
+		fromPos[i+1], toPos[i+1] = fromPos[i], toPos[i]
+		if l.op != diffmatchpatch.DiffInsert {
+			fromPos[i+1]++
+		}
+		if l.op != diffmatchpatch.DiffDelete {
+			toPos[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(lines); {
+		if lines[i].op == diffmatchpatch.DiffEqual {
+			i++
+			continue
+		}
+		start := max(0, i-diffContext)
+		end := hunkEnd(lines, i)
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(fromPos[start], fromPos[end]), hunkRange(toPos[start], toPos[end]))
+		for _, l := range lines[start:end] {
+			switch l.op {
+			case diffmatchpatch.DiffDelete:
+				sb.WriteByte('-')
+			case diffmatchpatch.DiffInsert:
+				sb.WriteByte('+')
+			default:
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// DiffStat returns numbers of added and removed lines of to text comparing to from.
+func DiffStat(from, to string) (added, removed int) {
+	if from == to {
+		return 0, 0
+	}
+	for _, l := range diffLines(from, to) {
+		switch l.op {
+		case diffmatchpatch.DiffInsert:
+			added++
+		case diffmatchpatch.DiffDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// diffLines returns line-by-line diff of texts.
+func diffLines(from, to string) []diffLine {
+	dmp := diffmatchpatch.New()
+	fromChars, toChars, lineArray := dmp.DiffLinesToChars(from, to)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(fromChars, toChars, false), lineArray)
+
+	var lines []diffLine
+	for _, d := range diffs {
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text == "" {
+				continue
+			}
+			lines = append(lines, diffLine{op: d.Type, text: text})
+		}
+	}
+	return lines
+}
+
+// hunkEnd returns end index of hunk which has first change at start index,
+// changes separated by less than two contexts of equal lines are merged into one hunk.
+func hunkEnd(lines []diffLine, start int) int {
+	end := start
+	for end < len(lines) {
+		if lines[end].op != diffmatchpatch.DiffEqual {
+			end++
+			continue
+		}
+		next := end
+		for next < len(lines) && lines[next].op == diffmatchpatch.DiffEqual {
+			next++
+		}
+		if next == len(lines) || next-end > 2*diffContext {
+			return min(len(lines), end+diffContext)
+		}
+		end = next
+	}
+	return end
+}
+
+// hunkRange formats hunk range of lines after from until to position.
+func hunkRange(from, to int) string {
+	count := to - from
+	if count == 0 {
+		return fmt.Sprintf("%d,0", from)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", from+1)
+	}
+	return fmt.Sprintf("%d,%d", from+1, count)
+}
@@ -119,6 +119,27 @@ func TestCamelToSnake(t *testing.T) {
 	}
 }
 
+func TestSnakeToCamel(t *testing.T) {
+	tests := map[string]string{
+		"DB_HOST":        "DBHost",
+		"HTTP_PORT":      "HTTPPort",
+		"API_URL":        "APIURL",
+		"user_id":        "UserID",
+		"LOG_LEVEL":      "LogLevel",
+		"S3_BUCKET":      "S3Bucket",
+		"APP__NAME_":     "AppName",
+		"app.name-value": "AppNameValue",
+		"ЮНИ_КОД":        "ЮниКод",
+		"":               "",
+	}
+
+	for input, expected := range tests {
+		if got := SnakeToCamel(input); got != expected {
+			t.Errorf("unexpected result for %q: got %q, want %q", input, got, expected)
+		}
+	}
+}
+
 func TestUnescapeGlob(t *testing.T) {
 	tests := map[string]string{
 		`"foo"`: `foo`,
@@ -140,3 +161,68 @@ func TestUnescapeGlob(t *testing.T) {
 		}
 	}
 }
+
+func TestUnifiedDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		from   string
+		to     string
+		expect string
+	}{
+		{
+			name: "equal",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+		},
+		{
+			name:   "change",
+			from:   "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:     "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expect: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "two hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expect: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name:   "from empty",
+			from:   "",
+			to:     "a\n",
+			expect: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:   "no newline at end",
+			from:   "a\nb",
+			to:     "a\nc\n",
+			expect: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := UnifiedDiff("old", "new", tc.from, tc.to)
+			if actual != tc.expect {
+				t.Errorf("Unexpected diff:\nGot:\n%s\nExpected:\n%s", actual, tc.expect)
+			}
+		})
+	}
+}
+
+func TestDiffStat(t *testing.T) {
+	for _, tc := range []struct {
+		from, to       string
+		added, removed int
+	}{
+		{"a\nb\n", "a\nb\n", 0, 0},
+		{"a\nb\n", "a\nc\nd\n", 2, 1},
+		{"", "a\n", 1, 0},
+		{"a\nb\nc\n", "b\n", 0, 2},
+	} {
+		added, removed := DiffStat(tc.from, tc.to)
+		if added != tc.added || removed != tc.removed {
+			t.Errorf("DiffStat(%q, %q) = +%d -%d, expected +%d -%d", tc.from, tc.to, added, removed, tc.added, tc.removed)
+		}
+	}
+}
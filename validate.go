@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/types"
+)
+
+// validationIssue is a problem of environment variable found by validate command.
+type validationIssue struct {
+	// Name of the variable.
+	Name string
+	// Msg describes the problem.
+	Msg string
+	// Warning is true if the issue doesn't fail validation.
+	Warning bool
+}
+
+func (i validationIssue) String() string {
+	if i.Warning {
+		return fmt.Sprintf("warning %s: %s", i.Name, i.Msg)
+	}
+	return fmt.Sprintf("error   %s: %s", i.Name, i.Msg)
+}
+
+// validateCommand validates environment variables of env file or
+// process environment against documented spec, it returns exit code:
+// 0 if environment is valid, 1 if it has errors and 2 if it can't be validated.
+func validateCommand(args []string, stdout, stderr io.Writer) int {
+	var c Config
+	f := flag.NewFlagSet("envdoc validate", flag.ContinueOnError)
+	f.SetOutput(stderr)
+	var envFile, spec string
+	var strict bool
+	f.StringVar(&envFile, "env-file", "", "Dotenv file to validate, default is the process environment")
+	f.StringVar(&spec, "spec", "", "JSON documentation file to use as a spec instead of parsing sources")
+	f.BoolVar(&strict, "strict", false, "Fail on unknown variables with documented prefixes")
+	if err := c.parseFlags(f, args); err != nil {
+		return 2
+	}
+
+	scopes, err := validationSpec(&c, spec, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load spec: %v\n", err)
+		return 2
+	}
+	env, err := validationEnv(envFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load environment: %v\n", err)
+		return 2
+	}
+
+	issues := validateEnv(scopes, env, strict)
+	var errs, warns int
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+		if issue.Warning {
+			warns++
+		} else {
+			errs++
+		}
+	}
+	fmt.Fprintf(stdout, "%d errors, %d warnings\n", errs, warns)
+	if errs > 0 {
+		return 1
+	}
+	return 0
+}
+
+// validationSpec reads spec from JSON documentation file,
+// or parses sources of config if spec file is not set.
+func validationSpec(c *Config, spec string, stderr io.Writer) ([]*types.EnvScope, error) {
+	if spec != "" {
+		f, err := os.Open(spec)
+		if err != nil {
+			return nil, fmt.Errorf("open spec: %w", err)
+		}
+		defer f.Close()
+		return render.ReadJSON(f)
+	}
+	_, scopes, err := commandScopes(c, stderr)
+	return scopes, err
+}
+
+// validationEnv reads variables of dotenv file, or of process environment
+// if file is not set.
+func validationEnv(file string) (map[string]string, error) {
+	env := make(map[string]string)
+	if file == "" {
+		for _, kv := range os.Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			env[name] = value
+		}
+		return env, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open env file: %w", err)
+	}
+	defer f.Close()
+	vars, err := parseDotenv(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse env file: %w", err)
+	}
+	for _, v := range vars {
+		env[v.Name] = v.Value
+	}
+	return env, nil
+}
+
+// validateEnv checks env against documented variables of scopes: required
+// variables are set, non-empty variables are not empty, values can be parsed
+// as field types and files of file variables exist. Unknown variables
+// with prefixes of documented variables are reported as warnings,
+// or as errors in strict mode.
+func validateEnv(scopes []*types.EnvScope, env map[string]string, strict bool) []validationIssue {
+	var issues []validationIssue
+	documented := make(map[string]bool)
+	var walk func(items []*types.EnvDocItem)
+	walk = func(items []*types.EnvDocItem) {
+		for _, item := range items {
+			if item.Name != "" && !documented[item.Name] {
+				documented[item.Name] = true
+				if msg := validateVar(item, env); msg != "" {
+					issues = append(issues, validationIssue{Name: item.Name, Msg: msg})
+				}
+			}
+			walk(item.Children)
+		}
+	}
+	for _, scope := range scopes {
+		walk(scope.Vars)
+	}
+
+	prefixes := make(map[string]bool)
+	for name := range documented {
+		if i := strings.Index(name, "_"); i > 0 {
+			prefixes[name[:i+1]] = true
+		}
+	}
+	var unknown []validationIssue
+	for name := range env {
+		if documented[name] {
+			continue
+		}
+		if i := strings.Index(name, "_"); i > 0 && prefixes[name[:i+1]] {
+			unknown = append(unknown, validationIssue{
+				Name:    name,
+				Msg:     fmt.Sprintf("unknown variable with documented prefix %s", name[:i+1]),
+				Warning: !strict,
+			})
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].Name < unknown[j].Name
+	})
+	return append(issues, unknown...)
+}
+
+// validateVar returns a problem of documented variable value,
+// or empty string if value is valid.
+func validateVar(item *types.EnvDocItem, env map[string]string) string {
+	value, set := env[item.Name]
+	opts := item.Opts
+	if !set && opts.Required && opts.Default == "" {
+		return "required variable is not set"
+	}
+	if !set {
+		value = opts.Default
+	}
+	if value == "" {
+		// unset variable without default is empty too
+		if opts.NonEmpty {
+			return "variable must not be empty"
+		}
+		return ""
+	}
+	if opts.FromFile {
+		if _, err := os.Stat(value); err != nil {
+			return fmt.Sprintf("file %q does not exist", value)
+		}
+		return ""
+	}
+	if !set {
+		return ""
+	}
+	if err := checkValue(item.Type, opts.Separator, value); err != nil {
+		return fmt.Sprintf("invalid value %q of %s type: %v", value, item.Type, err)
+	}
+	return ""
+}
+
+// checkValue checks that value can be parsed as typ the same way
+// as env libraries do, values of unknown types are not checked.
+func checkValue(typ, sep, value string) error {
+	if sep == "" {
+		sep = ","
+	}
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		return checkValue(typ[1:], sep, value)
+	case strings.HasPrefix(typ, "[]"):
+		for _, v := range strings.Split(value, sep) {
+			if err := checkScalar(typ[2:], v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case strings.HasPrefix(typ, "map["):
+		keyType, valType, _ := strings.Cut(typ[len("map["):], "]")
+		for _, pair := range strings.Split(value, sep) {
+			k, v, ok := strings.Cut(pair, ":")
+			if !ok {
+				return fmt.Errorf("invalid map entry %q, expected key:value", pair)
+			}
+			if err := checkScalar(keyType, k); err != nil {
+				return fmt.Errorf("invalid map key %q: %w", k, err)
+			}
+			if err := checkScalar(valType, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return checkScalar(typ, value)
+}
+
+var numTypeBits = map[string]int{
+	"int": 0, "int8": 8, "int16": 16, "int32": 32, "int64": 64,
+	"uint": 0, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64,
+	"float32": 32, "float64": 64,
+}
+
+func checkScalar(typ, value string) error {
+	var err error
+	switch typ {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int", "int8", "int16", "int32", "int64":
+		_, err = strconv.ParseInt(value, 10, numTypeBits[typ])
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		_, err = strconv.ParseUint(value, 10, numTypeBits[typ])
+	case "float32", "float64":
+		_, err = strconv.ParseFloat(value, numTypeBits[typ])
+	case "time.Duration":
+		_, err = time.ParseDuration(value)
+	case "time.Location":
+		_, err = time.LoadLocation(value)
+	case "url.URL":
+		_, err = url.Parse(value)
+	}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/g4s8/envdoc/render"
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func TestCheckValue(t *testing.T) {
+	for _, tc := range []struct {
+		typ, sep, value string
+		valid           bool
+	}{
+		{"int", "", "42", true},
+		{"int", "", "4x", false},
+		{"int8", "", "300", false},
+		{"uint", "", "-1", false},
+		{"bool", "", "true", true},
+		{"bool", "", "yes", false},
+		{"float64", "", "1.5", true},
+		{"time.Duration", "", "5s", true},
+		{"time.Duration", "", "5", false},
+		{"*int", "", "1", true},
+		{"[]int", ";", "1;2;3", true},
+		{"[]int", "", "1,b", false},
+		{"map[string]int", "", "a:1,b:2", true},
+		{"map[string]int", "", "a=1", false},
+		{"map[int]bool", "", "1:true,2:false", true},
+		{"map[int]bool", "", "a:true", false},
+		{"string", "", "anything", true},
+		{"Level", "", "anything", true},
+	} {
+		err := checkValue(tc.typ, tc.sep, tc.value)
+		testutils.AssertError(t, (err == nil) == tc.valid, "%s %q: unexpected result: %v", tc.typ, tc.value, err)
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{Name: "APP_PORT", Type: "int", Opts: types.EnvVarOptions{Required: true}},
+				{Name: "APP_HOST", Type: "string", Opts: types.EnvVarOptions{Required: true, Default: "localhost"}},
+				{Name: "APP_NAME", Type: "string", Opts: types.EnvVarOptions{Required: true, NonEmpty: true}},
+				{Name: "APP_LABEL", Type: "string", Opts: types.EnvVarOptions{NonEmpty: true}},
+				{Name: "APP_MODE", Type: "string", Opts: types.EnvVarOptions{NonEmpty: true, Default: "dev"}},
+				{Name: "APP_TIMEOUT", Type: "time.Duration"},
+				{
+					Doc: "Database config.",
+					Children: []*types.EnvDocItem{
+						{Name: "DB_PASSWORD_FILE", Type: "string", Opts: types.EnvVarOptions{FromFile: true}},
+						{Name: "DB_CERT_FILE", Type: "string", Opts: types.EnvVarOptions{FromFile: true}},
+					},
+				},
+			},
+		},
+	}
+	env := map[string]string{
+		"APP_NAME":         "",
+		"APP_TIMEOUT":      "5",
+		"APP_TIMEUOT":      "5s",
+		"DB_PASSWORD_FILE": secret,
+		"DB_CERT_FILE":     "/missing/cert.pem",
+		"HOME":             "/root",
+	}
+	issues := validateEnv(scopes, env, false)
+	expect := []string{
+		"error   APP_PORT: required variable is not set",
+		"error   APP_NAME: variable must not be empty",
+		"error   APP_LABEL: variable must not be empty",
+		`error   APP_TIMEOUT: invalid value "5" of time.Duration type: time: missing unit in duration "5"`,
+		`error   DB_CERT_FILE: file "/missing/cert.pem" does not exist`,
+		"warning APP_TIMEUOT: unknown variable with documented prefix APP_",
+	}
+	actual := make([]string, len(issues))
+	for i, issue := range issues {
+		actual[i] = issue.String()
+	}
+	testutils.AssertFatal(t, strings.Join(actual, "\n") == strings.Join(expect, "\n"),
+		"unexpected issues:\n%s", strings.Join(actual, "\n"))
+
+	issues = validateEnv(scopes, env, true)
+	testutils.AssertError(t, !issues[len(issues)-1].Warning, "expected error for unknown variable in strict mode")
+}
+
+func TestValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	scopes := []*types.EnvScope{
+		{
+			Name: "Config",
+			Vars: []*types.EnvDocItem{
+				{Name: "PORT", Type: "int", Opts: types.EnvVarOptions{Required: true}},
+			},
+		},
+	}
+	var spec strings.Builder
+	if err := render.NewRenderer(types.OutFormatJSON, true).Render(scopes, &spec); err != nil {
+		t.Fatalf("render spec: %v", err)
+	}
+	specFile := filepath.Join(dir, "env.json")
+	if err := os.WriteFile(specFile, []byte(spec.String()), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+
+	run := func(env string) (int, string) {
+		t.Helper()
+		envFile := filepath.Join(dir, ".env")
+		if err := os.WriteFile(envFile, []byte(env), 0o600); err != nil {
+			t.Fatalf("write env file: %v", err)
+		}
+		var stdout, stderr strings.Builder
+		code := validateCommand([]string{"-spec", specFile, "-env-file", envFile}, &stdout, &stderr)
+		return code, stdout.String() + stderr.String()
+	}
+
+	code, out := run("PORT=8080\n")
+	testutils.AssertError(t, code == 0, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, out == "0 errors, 0 warnings\n", "unexpected output: %s", out)
+
+	code, out = run("PORT=http\n")
+	testutils.AssertError(t, code == 1, "unexpected exit code %d: %s", code, out)
+	testutils.AssertError(t, strings.Contains(out, `error   PORT: invalid value "http" of int type`), "unexpected output: %s", out)
+
+	code, out = run("PORT\n")
+	testutils.AssertError(t, code == 2, "unexpected exit code %d: %s", code, out)
+}
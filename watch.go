@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/g4s8/envdoc/ast"
+	"github.com/g4s8/envdoc/utils"
+)
+
+// DefaultWatchInterval is a default polling interval of watch mode.
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// fileStamp identifies version of watched file.
+type fileStamp struct {
+	modTime int64
+	size    int64
+}
+
+// watcher polls Go files of parsed directories of configs
+// and regenerates outputs of configs affected by changes.
+type watcher struct {
+	cfgs     []Config
+	interval time.Duration
+	out      io.Writer
+
+	// files are stamps of watched files of each config
+	files []map[string]fileStamp
+}
+
+func newWatcher(cfgs []Config, out io.Writer) *watcher {
+	interval := DefaultWatchInterval
+	if len(cfgs) > 0 && cfgs[0].WatchInterval > 0 {
+		interval = cfgs[0].WatchInterval
+	}
+	return &watcher{
+		cfgs:     cfgs,
+		interval: interval,
+		out:      out,
+	}
+}
+
+// run polls files until ctx is done.
+func (w *watcher) run(ctx context.Context) error {
+	w.files = make([]map[string]fileStamp, len(w.cfgs))
+	for i, cfg := range w.cfgs {
+		files, err := watchedFiles(cfg)
+		if err != nil {
+			return fmt.Errorf("watch %s: %w", cfg.Dir, err)
+		}
+		w.files[i] = files
+	}
+	fmt.Fprintf(w.out, "Watching for changes in %s, press Ctrl+C to stop\n", w.dirs())
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	pending := make([]bool, len(w.cfgs))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// outputs are regenerated after one poll interval without changes,
+			// so a burst of saves causes only one regeneration
+			if w.poll(pending) {
+				continue
+			}
+			for i, ok := range pending {
+				if ok {
+					w.regenerate(w.cfgs[i])
+					pending[i] = false
+				}
+			}
+		}
+	}
+}
+
+// poll rescans watched files and marks configs with changed files as pending,
+// it returns true if any file was changed.
+func (w *watcher) poll(pending []bool) bool {
+	var changed bool
+	for i, cfg := range w.cfgs {
+		files, err := watchedFiles(cfg)
+		if err != nil {
+			fmt.Fprintf(w.out, "%s failed to scan %s: %v\n", time.Now().Format(time.TimeOnly), cfg.Dir, err)
+			continue
+		}
+		if filesChanged(w.files[i], files) {
+			pending[i] = true
+			changed = true
+		}
+		w.files[i] = files
+	}
+	return changed
+}
+
+// regenerate generates outputs of cfg and writes changed outputs
+// with a short diff summary.
+func (w *watcher) regenerate(cfg Config) {
+	now := time.Now().Format(time.TimeOnly)
+	var opts []ast.ParserConfigOption
+	if cache := newDiskCache(cfg); cache != nil {
+		opts = append(opts, ast.WithDiskCache(cache))
+	}
+	outCfgs, contents, err := generateOutputs(cfg, nil, opts...)
+	if err != nil {
+		fmt.Fprintf(w.out, "%s failed to generate: %v\n", now, err)
+		return
+	}
+	for i, oc := range outCfgs {
+		current, expected, err := outputContent(oc, contents[i])
+		if err != nil {
+			fmt.Fprintf(w.out, "%s failed to update %s: %v\n", now, oc.OutFile, err)
+			continue
+		}
+		if current != nil && bytes.Equal(current, expected) {
+			fmt.Fprintf(w.out, "%s unchanged %s\n", now, oc.OutFile)
+			continue
+		}
+		if err := writeOutput(oc, contents[i], w.out); err != nil {
+			fmt.Fprintf(w.out, "%s failed to update %s: %v\n", now, oc.OutFile, err)
+			continue
+		}
+		added, removed := utils.DiffStat(string(current), string(expected))
+		fmt.Fprintf(w.out, "%s updated %s (+%d -%d lines)\n", now, oc.OutFile, added, removed)
+	}
+}
+
+func (w *watcher) dirs() string {
+	dirs := make([]string, 0, len(w.cfgs))
+	seen := make(map[string]bool, len(w.cfgs))
+	for _, cfg := range w.cfgs {
+		if !seen[cfg.Dir] {
+			seen[cfg.Dir] = true
+			dirs = append(dirs, cfg.Dir)
+		}
+	}
+	return strings.Join(dirs, ", ")
+}
+
+// watchedFiles returns stamps of Go files in directories parsed for cfg
+// and of its template file.
+func watchedFiles(cfg Config) (map[string]fileStamp, error) {
+	dirs, err := newParser(cfg).Dirs(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]fileStamp)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("read dir: %w", err)
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				// file is removed while scanning
+				continue
+			}
+			res[filepath.Join(dir, e.Name())] = fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		}
+	}
+	if cfg.TemplateFile != "" {
+		if info, err := os.Stat(cfg.TemplateFile); err == nil {
+			res[cfg.TemplateFile] = fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		}
+	}
+	return res, nil
+}
+
+// filesChanged checks if any file was added, removed or changed.
+func filesChanged(prev, next map[string]fileStamp) bool {
+	if len(prev) != len(next) {
+		return true
+	}
+	for path, stamp := range next {
+		if old, ok := prev[path]; !ok || old != stamp {
+			return true
+		}
+	}
+	return false
+}
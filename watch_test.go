@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/g4s8/envdoc/testutils"
+	"github.com/g4s8/envdoc/types"
+)
+
+func TestFilesChanged(t *testing.T) {
+	prev := map[string]fileStamp{"a.go": {modTime: 1, size: 10}, "b.go": {modTime: 1, size: 20}}
+	for _, tc := range []struct {
+		name    string
+		next    map[string]fileStamp
+		changed bool
+	}{
+		{"same", map[string]fileStamp{"a.go": {modTime: 1, size: 10}, "b.go": {modTime: 1, size: 20}}, false},
+		{"modified", map[string]fileStamp{"a.go": {modTime: 2, size: 10}, "b.go": {modTime: 1, size: 20}}, true},
+		{"removed", map[string]fileStamp{"a.go": {modTime: 1, size: 10}}, true},
+		{"renamed", map[string]fileStamp{"a.go": {modTime: 1, size: 10}, "c.go": {modTime: 1, size: 20}}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.AssertError(t, filesChanged(prev, tc.next) == tc.changed,
+				"expected changed=%v", tc.changed)
+		})
+	}
+}
+
+// syncBuffer is a strings.Builder safe for concurrent use.
+type syncBuffer struct {
+	mux sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func TestWatcher(t *testing.T) {
+	t.Setenv("ENVDOC_CACHE", "off")
+	dir := t.TempDir()
+	src := filepath.Join(dir, "config.go")
+	out := filepath.Join(dir, ".env")
+	writeSrc := func(fields string) {
+		t.Helper()
+		content := "package a\n\ntype Config struct {\n" + fields + "}\n"
+		if err := os.WriteFile(src, []byte(content), 0o600); err != nil {
+			t.Fatalf("write source: %v", err)
+		}
+	}
+	writeSrc("\t// Port doc.\n\tPort int `env:\"PORT\"`\n")
+	if err := os.WriteFile(out, []byte("outdated\n"), 0o600); err != nil {
+		t.Fatalf("write output: %v", err)
+	}
+
+	cfg := Config{
+		Dir:       dir,
+		FileGlob:  "*",
+		TypeGlob:  "*",
+		OutFile:   out,
+		OutFormat: types.OutFormatEnv,
+		Target:    types.TargetTypeCaarlos0,
+		TagName:   "env",
+		NoStyles:  true,
+	}
+	var stdout syncBuffer
+	w := newWatcher([]Config{cfg}, &stdout)
+	w.interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.run(ctx) }()
+	waitFor := func(what string, check func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !check() {
+			if time.Now().After(deadline) {
+				cancel()
+				t.Fatalf("timeout waiting for %s, output:\n%s", what, stdout.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitFor("start", func() bool { return strings.Contains(stdout.String(), "Watching for changes in "+dir) })
+
+	writeSrc("\t// Port doc.\n\tPort int `env:\"PORT\"`\n\t// Host doc.\n\tHost string `env:\"HOST\"`\n")
+	waitFor("update", func() bool { return strings.Contains(stdout.String(), "updated "+out) })
+	data, err := os.ReadFile(out)
+	testutils.AssertFatal(t, err == nil, "read output: %v", err)
+	testutils.AssertError(t, strings.Contains(string(data), "PORT") && strings.Contains(string(data), "HOST"),
+		"unexpected output:\n%s", data)
+
+	cancel()
+	testutils.AssertError(t, <-done == nil, "unexpected watch error")
+}